@@ -6,16 +6,25 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/shehryarbajwa/browserbase-mini/internal/admission"
 	"github.com/shehryarbajwa/browserbase-mini/internal/api"
+	"github.com/shehryarbajwa/browserbase-mini/internal/artifacts"
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
 	contextmgr "github.com/shehryarbajwa/browserbase-mini/internal/context"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
 	"github.com/shehryarbajwa/browserbase-mini/internal/proxy"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
 	"github.com/shehryarbajwa/browserbase-mini/internal/ratelimit"
 	"github.com/shehryarbajwa/browserbase-mini/internal/region"
 	"github.com/shehryarbajwa/browserbase-mini/internal/session"
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/internal/usage"
+	"github.com/shehryarbajwa/browserbase-mini/internal/webhook"
 )
 
 func main() {
@@ -26,8 +35,19 @@ func main() {
 
 	log.Println("Starting Browserbase Mini...")
 
+	// Browsers run in Docker by default; "local" runs Chromium processes
+	// directly for machines without Docker
+	backends := region.DockerBackends
+	switch backend := os.Getenv("BROWSER_BACKEND"); backend {
+	case "", "docker":
+	case "local":
+		backends = region.LocalBackends(os.Getenv("CHROME_PATH"))
+	default:
+		log.Fatalf("BROWSER_BACKEND must be docker or local, got %q", backend)
+	}
+
 	// Initialize region manager
-	regionMgr, err := region.NewManager()
+	regionMgr, err := region.NewManager(backends)
 	if err != nil {
 		log.Fatalf("Failed to create region manager: %v", err)
 	}
@@ -51,10 +71,155 @@ func main() {
 	}
 	log.Println("✓ Context manager initialized")
 
+	// Initialize durable session store
+	sessionStore, err := store.NewFileSessionStore("./storage/sessions")
+	if err != nil {
+		log.Fatalf("Failed to create session store: %v", err)
+	}
+	log.Println("✓ Session store initialized")
+
+	// Initialize per-session artifact storage
+	artifactStore, err := artifacts.NewStore("./storage/artifacts", artifacts.Limits{
+		ConsoleLogBytes: 5 * 1024 * 1024,
+		NetworkLogBytes: 50 * 1024 * 1024,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create artifact store: %v", err)
+	}
+	log.Println("✓ Artifact store initialized")
+
+	// Initialize browser-minute metering
+	usageStore, err := store.NewFileUsageStore("./storage/usage")
+	if err != nil {
+		log.Fatalf("Failed to create usage store: %v", err)
+	}
+	meter, err := usage.NewMeter(usageStore)
+	if err != nil {
+		log.Fatalf("Failed to create usage meter: %v", err)
+	}
+	log.Println("✓ Usage meter initialized")
+
+	// Initialize the project registry
+	projectStore, err := store.NewFileProjectStore("./storage/projects")
+	if err != nil {
+		log.Fatalf("Failed to create project store: %v", err)
+	}
+	projectMgr, err := project.NewManager(projectStore)
+	if err != nil {
+		log.Fatalf("Failed to create project manager: %v", err)
+	}
+	log.Println("✓ Project registry initialized")
+
+	// Initialize per-project quotas
+	quotaStore, err := store.NewFileQuotaStore("./storage/quotas")
+	if err != nil {
+		log.Fatalf("Failed to create quota store: %v", err)
+	}
+	quotaMgr, err := quota.NewManager(quotaStore)
+	if err != nil {
+		log.Fatalf("Failed to create quota manager: %v", err)
+	}
+	log.Println("✓ Quota manager initialized")
+
+	// Clients connect to sessions through this server's CDP proxy
+	publicURL := os.Getenv("PUBLIC_WS_URL")
+	if publicURL == "" {
+		publicURL = "ws://localhost:8080"
+	}
+
+	// Cap sessions on this host; a tenth of capacity is held for interactive sessions
+	hostCapacity := 50
+	if value := os.Getenv("HOST_CAPACITY"); value != "" {
+		capacity, err := strconv.Atoi(value)
+		if err != nil || capacity < 1 {
+			log.Fatalf("HOST_CAPACITY must be a positive integer, got %q", value)
+		}
+		hostCapacity = capacity
+	}
+	admissionCtl := admission.NewController(hostCapacity, hostCapacity/10)
+	log.Printf("✓ Admission control initialized (%d sessions per host)", hostCapacity)
+
 	// Initialize session manager
-	sessionMgr := session.NewManager(regionMgr, ctxMgr)
+	sessionMgr, err := session.NewManager(regionMgr, ctxMgr, sessionStore, artifactStore, meter, quotaMgr, projectMgr, admissionCtl, publicURL)
+	if err != nil {
+		log.Fatalf("Failed to create session manager: %v", err)
+	}
 	log.Println("✓ Session manager initialized")
 
+	// Reconcile restored sessions with running containers, then keep reaping
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	if err := sessionMgr.Reconcile(ctx); err != nil {
+		log.Printf("⚠️ Startup reconciliation failed: %v", err)
+	}
+	// Keep pre-started containers per region so launches skip container startup.
+	// They get the default /dev/shm size, which cannot change once created.
+	warmPool := browser.WarmPoolConfig{
+		TTL:     30 * time.Minute,
+		Dir:     "./storage/warm",
+		ShmSize: project.DefaultResources.ShmSizeMB << 20,
+	}
+	if value := os.Getenv("WARM_POOL_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			log.Fatalf("WARM_POOL_SIZE must be a non-negative integer, got %q", value)
+		}
+		warmPool.Size = size
+	}
+	if value := os.Getenv("WARM_POOL_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("WARM_POOL_TTL must be a positive duration, got %q", value)
+		}
+		warmPool.TTL = ttl
+	}
+	if err := regionMgr.StartWarmPools(bgCtx, warmPool); err != nil {
+		log.Fatalf("Failed to start warm pools: %v", err)
+	}
+	if warmPool.Size > 0 {
+		log.Printf("✓ Warm pools started (%d containers per region, %s TTL)", warmPool.Size, warmPool.TTL)
+	}
+
+	sessionMgr.StartReconciler(bgCtx, time.Minute)
+	log.Println("✓ Container reconciler started (every 1m)")
+
+	sessionMgr.StartHealthMonitor(bgCtx, 5*time.Second)
+	log.Println("✓ Health monitor started (every 5s)")
+
+	sessionMgr.StartIdleMonitor(bgCtx, 5*time.Second)
+	log.Println("✓ Idle monitor started (every 5s)")
+
+	sessionMgr.StartQuotaMonitor(bgCtx, 10*time.Second)
+	log.Println("✓ Quota monitor started (every 10s)")
+
+	sessionMgr.StartDownloadJanitor(bgCtx, time.Hour, 7*24*time.Hour)
+	log.Println("✓ Download janitor started (7 day retention)")
+
+	retentionHours := 24
+	if value := os.Getenv("SESSION_RETENTION_HOURS"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours < 1 {
+			log.Fatalf("SESSION_RETENTION_HOURS must be a positive integer, got %q", value)
+		}
+		retentionHours = hours
+	}
+	sessionMgr.StartEvictor(bgCtx, 10*time.Minute, time.Duration(retentionHours)*time.Hour)
+	log.Printf("✓ Session evictor started (ended sessions leave memory after %dh)", retentionHours)
+
+	// Initialize webhook delivery. Start replays events published since the
+	// last one dispatched, including those from restore and reconcile above.
+	webhookStore, err := store.NewFileWebhookStore("./storage/webhooks")
+	if err != nil {
+		log.Fatalf("Failed to create webhook store: %v", err)
+	}
+	webhookMgr, err := webhook.NewManager(webhookStore, sessionMgr.Events())
+	if err != nil {
+		log.Fatalf("Failed to create webhook manager: %v", err)
+	}
+	webhookMgr.Start(bgCtx)
+	log.Println("✓ Webhook delivery started")
+
 	// Initialize WebSocket proxy
 	proxyServer := proxy.NewServer(sessionMgr)
 	log.Println("✓ WebSocket proxy initialized")
@@ -66,8 +231,10 @@ func main() {
 	// Setup HTTP handlers
 	sessionHandler := api.NewHandler(sessionMgr)
 	contextHandler := api.NewContextHandler(ctxMgr)
+	projectHandler := api.NewProjectHandler(projectMgr, quotaMgr)
+	webhookHandler := api.NewWebhookHandler(webhookMgr)
 
-	router := sessionHandler.SetupRoutes(contextHandler, proxyServer, rateLimiter)
+	router := sessionHandler.SetupRoutes(contextHandler, projectHandler, webhookHandler, proxyServer, rateLimiter)
 	log.Println("✓ HTTP routes configured")
 
 	// Create HTTP server
@@ -107,5 +274,8 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Idle warm containers would otherwise wait for the next start's reaper
+	regionMgr.DrainWarmPools()
+
 	log.Println("✅ Server stopped cleanly")
 }
@@ -0,0 +1,228 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// retryAfter is the back-off suggested to callers turned away for capacity
+const retryAfter = 10 * time.Second
+
+// CapacityError is returned when the host has no capacity for a session
+type CapacityError struct {
+	Capacity   int
+	RetryAfter time.Duration
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("host capacity of %d sessions is exhausted, retry in %s", e.Capacity, e.RetryAfter)
+}
+
+// Ticket identifies a session asking for host capacity
+type Ticket struct {
+	ProjectID string
+	Weight    int
+	Priority  models.Priority
+}
+
+// waiter is a ticket queued for capacity
+type waiter struct {
+	Ticket
+	granted bool
+	ready   chan struct{} // closed once capacity is granted
+}
+
+// Controller caps the number of sessions on the host. When capacity is short,
+// interactive sessions go ahead of batch ones, and within a class capacity is
+// shared between projects in proportion to their weights.
+type Controller struct {
+	mu       sync.Mutex
+	capacity int
+	reserve  int            // capacity only interactive sessions may take
+	inUse    int            // sessions admitted
+	held     map[string]int // projectID -> sessions admitted
+	queue    []*waiter      // in arrival order
+	rejected int64
+}
+
+// NewController creates a controller for capacity sessions, holding back
+// reserve of them for interactive sessions
+func NewController(capacity, reserve int) *Controller {
+	if reserve >= capacity {
+		reserve = capacity - 1
+	}
+	if reserve < 0 {
+		reserve = 0
+	}
+
+	return &Controller{
+		capacity: capacity,
+		reserve:  reserve,
+		held:     make(map[string]int),
+	}
+}
+
+// Acquire admits a session, waiting up to wait for capacity. A session is
+// only admitted at once if nothing of the same or higher priority is queued.
+func (c *Controller) Acquire(ctx context.Context, t Ticket, wait time.Duration) error {
+	if t.Priority == "" {
+		t.Priority = models.PriorityInteractive
+	}
+	if t.Weight < 1 {
+		t.Weight = 1
+	}
+
+	c.mu.Lock()
+	if c.fits(t.Priority) && !c.queuedAtOrAbove(t.Priority) {
+		c.admit(t.ProjectID)
+		c.mu.Unlock()
+		return nil
+	}
+	if wait <= 0 {
+		c.rejected++
+		c.mu.Unlock()
+		return &CapacityError{Capacity: c.capacity, RetryAfter: retryAfter}
+	}
+
+	w := &waiter{Ticket: t, ready: make(chan struct{})}
+	c.queue = append(c.queue, w)
+	c.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w.granted {
+		return nil
+	}
+	for i, queued := range c.queue {
+		if queued == w {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			break
+		}
+	}
+	c.rejected++
+	// A batch waiter leaving can unblock interactive ones behind the reserve
+	c.dispatch()
+	return &CapacityError{Capacity: c.capacity, RetryAfter: retryAfter}
+}
+
+// Hold admits a session regardless of capacity, for sessions whose browser
+// is already running
+func (c *Controller) Hold(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.admit(projectID)
+}
+
+// Release returns a session's capacity and admits queued sessions
+func (c *Controller) Release(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inUse > 0 {
+		c.inUse--
+	}
+	if c.held[projectID] > 1 {
+		c.held[projectID]--
+	} else {
+		delete(c.held, projectID)
+	}
+	c.dispatch()
+}
+
+// Metrics reports capacity use and queue depth
+func (c *Controller) Metrics() models.AdmissionMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics := models.AdmissionMetrics{
+		Capacity: c.capacity,
+		InUse:    c.inUse,
+		Reserved: c.reserve,
+		Rejected: c.rejected,
+	}
+	for _, w := range c.queue {
+		if w.Priority == models.PriorityBatch {
+			metrics.QueuedBatch++
+		} else {
+			metrics.QueuedInteractive++
+		}
+	}
+	return metrics
+}
+
+// admit takes capacity for a project; c.mu must be held
+func (c *Controller) admit(projectID string) {
+	c.inUse++
+	c.held[projectID]++
+}
+
+// fits reports whether a session of the priority can be admitted now
+func (c *Controller) fits(priority models.Priority) bool {
+	free := c.capacity - c.inUse
+	if priority == models.PriorityBatch {
+		free -= c.reserve
+	}
+	return free > 0
+}
+
+// queuedAtOrAbove reports whether anything of at least the priority is queued
+func (c *Controller) queuedAtOrAbove(priority models.Priority) bool {
+	for _, w := range c.queue {
+		if priority == models.PriorityBatch || w.Priority != models.PriorityBatch {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch admits queued sessions while capacity allows. Interactive
+// sessions go first; within a class the next session comes from the project
+// holding the least capacity for its weight, oldest first on ties.
+func (c *Controller) dispatch() {
+	for len(c.queue) > 0 {
+		class := models.PriorityBatch
+		for _, w := range c.queue {
+			if w.Priority != models.PriorityBatch {
+				class = models.PriorityInteractive
+				break
+			}
+		}
+		if !c.fits(class) {
+			return
+		}
+
+		next := -1
+		var nextShare float64
+		for i, w := range c.queue {
+			if (w.Priority == models.PriorityBatch) != (class == models.PriorityBatch) {
+				continue
+			}
+			share := float64(c.held[w.ProjectID]) / float64(w.Weight)
+			if next == -1 || share < nextShare {
+				next, nextShare = i, share
+			}
+		}
+
+		w := c.queue[next]
+		c.queue = append(c.queue[:next], c.queue[next+1:]...)
+		c.admit(w.ProjectID)
+		w.granted = true
+		close(w.ready)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ListDownloads handles GET /v1/sessions/{id}/downloads. With format=zip it
+// returns every completed download in a single archive.
+func (h *Handler) ListDownloads(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	store := h.sessionMgr.Artifacts()
+
+	switch r.URL.Query().Get("format") {
+	case "":
+		downloads, err := store.ListDownloads(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(downloads)
+	case "zip":
+		// Archives can be large; don't let the server write timeout cut them off
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"session-%s-downloads.zip\"", id))
+		if err := store.WriteDownloadsZip(id, w); err != nil {
+			// Headers are already sent; the client sees a truncated archive
+			log.Printf("⚠️ Failed to write downloads archive for %s: %v", id[:8], err)
+		}
+	default:
+		http.Error(w, "format must be zip", http.StatusBadRequest)
+	}
+}
+
+// GetDownload handles GET /v1/sessions/{id}/downloads/{name}
+func (h *Handler) GetDownload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	file, err := h.sessionMgr.Artifacts().OpenDownload(id, vars["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
@@ -0,0 +1,849 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shehryarbajwa/browserbase-mini/internal/admission"
+	"github.com/shehryarbajwa/browserbase-mini/internal/api"
+	"github.com/shehryarbajwa/browserbase-mini/internal/artifacts"
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser/browsertest"
+	contextmgr "github.com/shehryarbajwa/browserbase-mini/internal/context"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
+	"github.com/shehryarbajwa/browserbase-mini/internal/proxy"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
+	"github.com/shehryarbajwa/browserbase-mini/internal/ratelimit"
+	"github.com/shehryarbajwa/browserbase-mini/internal/region"
+	"github.com/shehryarbajwa/browserbase-mini/internal/session"
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/internal/usage"
+	"github.com/shehryarbajwa/browserbase-mini/internal/webhook"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// testServer is the full API wired as in cmd/server, with fake browsers
+type testServer struct {
+	t        *testing.T
+	server   *httptest.Server
+	sessions *session.Manager
+	backends map[region.Region]*browsertest.Backend
+}
+
+// newTestServer starts the API on a loopback port. Each project may make
+// burst rate-limited requests before being throttled.
+func newTestServer(t *testing.T, burst int) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+
+	ts := &testServer{
+		t:        t,
+		backends: make(map[region.Region]*browsertest.Backend),
+	}
+
+	regionMgr, err := region.NewManager(func(r region.Region, basePort int) (browser.Backend, error) {
+		backend := browsertest.NewBackend(string(r))
+		ts.backends[r] = backend
+		return backend, nil
+	})
+	must(t, err)
+
+	ctxMgr, err := contextmgr.NewManager(filepath.Join(dir, "contexts"))
+	must(t, err)
+	sessionStore, err := store.NewFileSessionStore(filepath.Join(dir, "sessions"))
+	must(t, err)
+	artifactStore, err := artifacts.NewStore(filepath.Join(dir, "artifacts"), artifacts.Limits{
+		ConsoleLogBytes: 1024 * 1024,
+		NetworkLogBytes: 1024 * 1024,
+	})
+	must(t, err)
+	usageStore, err := store.NewFileUsageStore(filepath.Join(dir, "usage"))
+	must(t, err)
+	meter, err := usage.NewMeter(usageStore)
+	must(t, err)
+	projectStore, err := store.NewFileProjectStore(filepath.Join(dir, "projects"))
+	must(t, err)
+	projectMgr, err := project.NewManager(projectStore)
+	must(t, err)
+	quotaStore, err := store.NewFileQuotaStore(filepath.Join(dir, "quotas"))
+	must(t, err)
+	quotaMgr, err := quota.NewManager(quotaStore)
+	must(t, err)
+	webhookStore, err := store.NewFileWebhookStore(filepath.Join(dir, "webhooks"))
+	must(t, err)
+
+	// The connect URL base must be known before the server starts serving
+	ts.server = httptest.NewUnstartedServer(nil)
+	connectBase := "ws://" + ts.server.Listener.Addr().String()
+
+	sessionMgr, err := session.NewManager(regionMgr, ctxMgr, sessionStore, artifactStore, meter, quotaMgr, projectMgr, admission.NewController(50, 5), connectBase)
+	must(t, err)
+	ts.sessions = sessionMgr
+	webhookMgr, err := webhook.NewManager(webhookStore, sessionMgr.Events())
+	must(t, err)
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	sessionMgr.StartHealthMonitor(bgCtx, 100*time.Millisecond)
+	sessionMgr.StartIdleMonitor(bgCtx, 100*time.Millisecond)
+
+	handler := api.NewHandler(sessionMgr)
+	ts.server.Config.Handler = handler.SetupRoutes(
+		api.NewContextHandler(ctxMgr),
+		api.NewProjectHandler(projectMgr, quotaMgr),
+		api.NewWebhookHandler(webhookMgr),
+		proxy.NewServer(sessionMgr),
+		ratelimit.NewLimiter(100, burst),
+	)
+	ts.server.Start()
+
+	t.Cleanup(func() {
+		stopBackground()
+		ts.server.Close()
+		regionMgr.Close()
+	})
+
+	return ts
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// do sends a JSON request and returns the response with its body read
+func (ts *testServer) do(method, path string, body interface{}, header http.Header) (*http.Response, []byte) {
+	ts.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		must(ts.t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, ts.server.URL+path, reader)
+	must(ts.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	must(ts.t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	must(ts.t, err)
+	return resp, data
+}
+
+// expect sends a request, fails the test unless it returns status, and
+// decodes the response body into out when out is not nil
+func (ts *testServer) expect(status int, method, path string, body, out interface{}) {
+	ts.t.Helper()
+
+	resp, data := ts.do(method, path, body, nil)
+	if resp.StatusCode != status {
+		ts.t.Fatalf("%s %s: got %d, want %d: %s", method, path, resp.StatusCode, status, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			ts.t.Fatalf("%s %s: failed to decode %s: %v", method, path, data, err)
+		}
+	}
+}
+
+// createProject registers a project with the given concurrency limit
+func (ts *testServer) createProject(id string, concurrency int) {
+	ts.t.Helper()
+	ts.expect(http.StatusCreated, "POST", "/v1/projects", models.CreateProjectRequest{
+		ID:          id,
+		Concurrency: concurrency,
+	}, nil)
+}
+
+// createSession creates a session and fails the test unless it is RUNNING
+func (ts *testServer) createSession(req models.CreateSessionRequest) *models.Session {
+	ts.t.Helper()
+
+	var sess models.Session
+	ts.expect(http.StatusCreated, "POST", "/v1/sessions", req, &sess)
+	if sess.Status != models.StatusRunning {
+		ts.t.Fatalf("new session is %s, want %s", sess.Status, models.StatusRunning)
+	}
+	return &sess
+}
+
+// getSession fetches a session's current record
+func (ts *testServer) getSession(id string) *models.Session {
+	ts.t.Helper()
+
+	var sess models.Session
+	ts.expect(http.StatusOK, "GET", "/v1/sessions/"+id, nil, &sess)
+	return &sess
+}
+
+// waitForStatus polls a session until it reaches status or timeout passes
+func (ts *testServer) waitForStatus(id string, status models.SessionStatus, timeout time.Duration) *models.Session {
+	ts.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		sess := ts.getSession(id)
+		if sess.Status == status {
+			return sess
+		}
+		if time.Now().After(deadline) {
+			ts.t.Fatalf("session %s is %s after %s, want %s", id, sess.Status, timeout, status)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// bridgeScript returns the Puppeteer bridge. Without Node.js or the root
+// package's puppeteer-core the test is skipped locally and fails in CI.
+func bridgeScript(t *testing.T) string {
+	t.Helper()
+
+	script, err := filepath.Abs("../session/puppeteer.js")
+	must(t, err)
+
+	check := exec.Command("node", "-e", "require('puppeteer-core')")
+	check.Dir = filepath.Dir(script)
+	if out, err := check.CombinedOutput(); err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("the Puppeteer bridge cannot load puppeteer-core (run npm ci in the repository root): %v: %s", err, out)
+		}
+		t.Skipf("the Puppeteer bridge needs Node.js and puppeteer-core (run npm install in the repository root): %v: %s", err, out)
+	}
+	return script
+}
+
+// backend returns the fake backend of the default region
+func (ts *testServer) backend() *browsertest.Backend {
+	return ts.backends[region.RegionUSWest2]
+}
+
+// dial opens a CDP connection to a session through the debug proxy
+func (ts *testServer) dial(sess *models.Session) *websocket.Conn {
+	ts.t.Helper()
+
+	conn, resp, err := websocket.DefaultDialer.Dial(sess.ConnectURL, nil)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		ts.t.Fatalf("failed to connect to %s (status %d): %v", sess.ConnectURL, status, err)
+	}
+	return conn
+}
+
+// cdpResult is a CDP response as the tests read it
+type cdpResult struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call sends one CDP command and waits for its response
+func call(t *testing.T, conn *websocket.Conn, id int64, method string, params interface{}) cdpResult {
+	t.Helper()
+
+	command := map[string]interface{}{"id": id, "method": method}
+	if params != nil {
+		command["params"] = params
+	}
+	if err := conn.WriteJSON(command); err != nil {
+		t.Fatalf("failed to send %s: %v", method, err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var result cdpResult
+	if err := conn.ReadJSON(&result); err != nil {
+		t.Fatalf("no response to %s: %v", method, err)
+	}
+	if result.ID != id {
+		t.Fatalf("%s: got response for id %d, want %d", method, result.ID, id)
+	}
+	return result
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	sess := ts.createSession(models.CreateSessionRequest{
+		ProjectID:    "proj-e2e",
+		UserMetadata: map[string]string{"suite": "e2e"},
+	})
+	if want := "ws://" + ts.server.Listener.Addr().String() + "/v1/sessions/" + sess.ID + "/ws"; sess.ConnectURL != want {
+		t.Errorf("connectUrl = %s, want %s", sess.ConnectURL, want)
+	}
+	if sess.Region != string(region.RegionUSWest2) {
+		t.Errorf("region = %s, want %s", sess.Region, region.RegionUSWest2)
+	}
+	if ts.backend().Browser(sess.ID) == nil {
+		t.Fatal("no browser was launched for the session")
+	}
+
+	var listed []models.Session
+	ts.expect(http.StatusOK, "GET", "/v1/sessions?projectId=proj-e2e&metadata.suite=e2e", nil, &listed)
+	if len(listed) != 1 || listed[0].ID != sess.ID {
+		t.Fatalf("list returned %+v, want only session %s", listed, sess.ID)
+	}
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+sess.ID, nil, nil)
+
+	ended := ts.getSession(sess.ID)
+	if ended.Status != models.StatusCompleted || ended.EndReason != models.EndReasonRequested {
+		t.Errorf("deleted session is %s/%s, want %s/%s", ended.Status, ended.EndReason, models.StatusCompleted, models.EndReasonRequested)
+	}
+	if ended.EndedAt == nil {
+		t.Error("deleted session has no endedAt")
+	}
+	if running := ts.backend().Running(); running != 0 {
+		t.Errorf("%d browsers still running after delete", running)
+	}
+
+	ts.expect(http.StatusOK, "GET", "/v1/sessions?projectId=proj-e2e&status=RUNNING", nil, &listed)
+	if len(listed) != 0 {
+		t.Errorf("%d sessions still listed as running", len(listed))
+	}
+
+	// Ending a session twice is an error, as is creating one in an unknown project
+	ts.expect(http.StatusBadRequest, "DELETE", "/v1/sessions/"+sess.ID, nil, nil)
+	ts.expect(http.StatusNotFound, "POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-missing"}, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/sessions/does-not-exist", nil, nil)
+}
+
+func TestBrowserExit(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	sess := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	fake := ts.backend().Browser(sess.ID)
+	must(t, ts.backend().Crash(fake.ID, 137))
+
+	ended := ts.waitForStatus(sess.ID, models.StatusError, 5*time.Second)
+	if ended.EndReason != models.EndReasonBrowserExit {
+		t.Errorf("endReason = %s, want %s", ended.EndReason, models.EndReasonBrowserExit)
+	}
+	if ended.ExitCode == nil || *ended.ExitCode != 137 {
+		t.Errorf("exitCode = %v, want 137", ended.ExitCode)
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	var proj models.Project
+	ts.expect(http.StatusOK, "GET", "/v1/projects/proj-e2e", nil, &proj)
+	if proj.Resources != project.DefaultResources {
+		t.Errorf("new project has resources %+v, want the defaults %+v", proj.Resources, project.DefaultResources)
+	}
+
+	// Overrides replace the project's defaults field by field
+	sess := ts.createSession(models.CreateSessionRequest{
+		ProjectID: "proj-e2e",
+		Resources: &models.ResourceLimits{CPUs: 0.5, MemoryMB: 1024},
+	})
+	want := models.ResourceLimits{CPUs: 0.5, MemoryMB: 1024, ShmSizeMB: 512, PidsLimit: 1024}
+	if sess.Resources == nil || *sess.Resources != want {
+		t.Errorf("session resources = %+v, want %+v", sess.Resources, want)
+	}
+	launched := ts.backend().Browser(sess.ID).Resources
+	if launched.CPUs != 0.5 || launched.Memory != 1024<<20 || launched.ShmSize != 512<<20 || launched.PidsLimit != 1024 {
+		t.Errorf("browser launched with %+v", launched)
+	}
+
+	for _, limits := range []models.ResourceLimits{
+		{MemoryMB: 100},
+		{CPUs: -1},
+		{PidsLimit: 10},
+		{MemoryMB: 512, ShmSizeMB: 1024},
+	} {
+		ts.expect(http.StatusBadRequest, "POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-e2e", Resources: &limits}, nil)
+	}
+
+	// A browser over its memory limit ends the session with its own reason
+	must(t, ts.backend().OOMKill(ts.backend().Browser(sess.ID).ID))
+	ended := ts.waitForStatus(sess.ID, models.StatusError, 5*time.Second)
+	if ended.EndReason != models.EndReasonOOMKilled {
+		t.Errorf("endReason = %s, want %s", ended.EndReason, models.EndReasonOOMKilled)
+	}
+	if !strings.Contains(ended.ErrorReason, "1024MB") {
+		t.Errorf("errorReason %q does not mention the memory limit", ended.ErrorReason)
+	}
+
+	// New project defaults apply to the next session
+	ts.expect(http.StatusOK, "PATCH", "/v1/projects/proj-e2e", models.UpdateProjectRequest{
+		Resources: &models.ResourceLimits{MemoryMB: 4096, PidsLimit: 256},
+	}, nil)
+	sess = ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	if want := (models.ResourceLimits{MemoryMB: 4096, PidsLimit: 256}); *sess.Resources != want {
+		t.Errorf("session resources after the project update = %+v, want %+v", *sess.Resources, want)
+	}
+}
+
+func TestSessionStats(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	sess := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	fake := ts.backend().Browser(sess.ID)
+	fake.SetStats(browser.Stats{CPUPercent: 80, MemoryUsage: 900 << 20, MemoryLimit: 2048 << 20, NetworkRx: 1000, NetworkTx: 200})
+
+	var stats models.ResourceStats
+	ts.expect(http.StatusOK, "GET", "/v1/sessions/"+sess.ID+"/stats", nil, &stats)
+	if stats.CPUPercent != 80 || stats.MemoryBytes != 900<<20 || stats.MemoryLimitBytes != 2048<<20 || stats.NetworkRxBytes != 1000 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	resp, err := http.Get(ts.server.URL + "/v1/sessions/" + sess.ID + "/stats?stream=true")
+	must(t, err)
+	if got := resp.Header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("stream Content-Type = %s", got)
+	}
+	decoder := json.NewDecoder(resp.Body)
+	for i := 0; i < 2; i++ {
+		var reading models.ResourceStats
+		must(t, decoder.Decode(&reading))
+		if reading.NetworkTxBytes != 200 || reading.Timestamp.IsZero() {
+			t.Errorf("streamed reading %d = %+v", i, reading)
+		}
+	}
+	resp.Body.Close()
+
+	// Memory falls back after its peak while the byte counters keep growing
+	time.Sleep(300 * time.Millisecond)
+	fake.SetStats(browser.Stats{CPUPercent: 20, MemoryUsage: 300 << 20, MemoryLimit: 2048 << 20, NetworkRx: 5000, NetworkTx: 700, BlockWrite: 4096})
+	time.Sleep(300 * time.Millisecond)
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+sess.ID, nil, nil)
+
+	ended := ts.getSession(sess.ID)
+	if ended.Stats == nil {
+		t.Fatal("ended session has no stats")
+	}
+	if ended.Stats.PeakMemoryBytes != 900<<20 || ended.Stats.PeakCPUPercent != 80 {
+		t.Errorf("peaks = %dB / %.0f%%, want %dB / 80%%", ended.Stats.PeakMemoryBytes, ended.Stats.PeakCPUPercent, 900<<20)
+	}
+	if ended.Stats.NetworkRxBytes != 5000 || ended.Stats.NetworkTxBytes != 700 || ended.Stats.BlockWriteBytes != 4096 {
+		t.Errorf("totals = %+v, want the last reading's counters", *ended.Stats)
+	}
+	if ended.Stats.Samples < 2 || ended.Stats.AvgCPUPercent <= 20 || ended.Stats.AvgCPUPercent >= 80 {
+		t.Errorf("%d samples averaging %.1f%% CPU", ended.Stats.Samples, ended.Stats.AvgCPUPercent)
+	}
+
+	ts.expect(http.StatusBadRequest, "GET", "/v1/sessions/"+sess.ID+"/stats", nil, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/sessions/does-not-exist/stats", nil, nil)
+}
+
+func TestSetInputFiles(t *testing.T) {
+	script := bridgeScript(t)
+	ts := newTestServer(t, 100)
+	ts.sessions.SetBridgeScript(script)
+	ts.createProject("proj-e2e", 5)
+
+	sess := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	if ts.sessions.GetPuppeteerConnection(sess.ID) == nil {
+		t.Fatal("the Puppeteer bridge did not connect to the fake browser")
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "report.csv")
+	must(t, err)
+	part.Write([]byte("a,b\n1,2\n"))
+	must(t, form.Close())
+
+	req, err := http.NewRequest("POST", ts.server.URL+"/v1/sessions/"+sess.ID+"/uploads", &body)
+	must(t, err)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := http.DefaultClient.Do(req)
+	must(t, err)
+	var uploads []models.Upload
+	must(t, json.NewDecoder(resp.Body).Decode(&uploads))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || len(uploads) != 1 {
+		t.Fatalf("upload returned %d with %+v", resp.StatusCode, uploads)
+	}
+
+	ts.expect(http.StatusNoContent, "POST", "/v1/sessions/"+sess.ID+"/set-input-files", models.SetInputFilesRequest{
+		Selector: "input[type=file]",
+		Files:    []string{uploads[0].Path},
+	}, nil)
+	if got := ts.backend().Browser(sess.ID).InputFiles(); len(got) != 1 || got[0] != uploads[0].Path {
+		t.Errorf("file input holds %v, want [%s]", got, uploads[0].Path)
+	}
+
+	ts.expect(http.StatusBadRequest, "POST", "/v1/sessions/"+sess.ID+"/set-input-files", models.SetInputFilesRequest{
+		Selector: "#missing",
+		Files:    []string{uploads[0].Path},
+	}, nil)
+	ts.expect(http.StatusBadRequest, "POST", "/v1/sessions/"+sess.ID+"/set-input-files", models.SetInputFilesRequest{
+		Selector: "input[type=file]",
+		Files:    []string{uploads[0].Path + ".missing"},
+	}, nil)
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	t.Parallel()
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	for _, req := range []models.CreateSessionRequest{
+		{ProjectID: "proj-e2e", Timeout: 30},
+		{ProjectID: "proj-e2e", Timeout: 21601},
+		{ProjectID: "proj-e2e", IdleTimeout: 5},
+	} {
+		ts.expect(http.StatusBadRequest, "POST", "/v1/sessions", req, nil)
+	}
+
+	sess := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e", Timeout: 60, IdleTimeout: 10})
+	if got := sess.ExpiresAt.Sub(sess.StartedAt); got != time.Minute {
+		t.Errorf("expiresAt is %s after startedAt, want 1m", got)
+	}
+
+	var extended models.Session
+	ts.expect(http.StatusOK, "PATCH", "/v1/sessions/"+sess.ID, models.UpdateSessionRequest{ExtendBy: 60}, &extended)
+	if extended.Timeout != 120 || !extended.ExpiresAt.Equal(sess.ExpiresAt.Add(time.Minute)) {
+		t.Errorf("extended session has timeout %d expiring at %s, want 120 at %s", extended.Timeout, extended.ExpiresAt, sess.ExpiresAt.Add(time.Minute))
+	}
+
+	// Nothing touches the session, so the idle timeout ends it well before
+	// the hard timeout
+	ended := ts.waitForStatus(sess.ID, models.StatusTimedOut, 15*time.Second)
+	if ended.EndReason != models.EndReasonIdleTimeout {
+		t.Errorf("endReason = %s, want %s", ended.EndReason, models.EndReasonIdleTimeout)
+	}
+	if running := ts.backend().Running(); running != 0 {
+		t.Errorf("%d browsers still running after the idle timeout", running)
+	}
+
+	ts.expect(http.StatusBadRequest, "PATCH", "/v1/sessions/"+sess.ID, models.UpdateSessionRequest{ExtendBy: 60}, nil)
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 2)
+
+	first := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+
+	resp, body := ts.do("POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-e2e"}, nil)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "concurrency limit") {
+		t.Fatalf("third session: got %d %s, want a concurrency limit rejection", resp.StatusCode, body)
+	}
+	if launches := ts.backend().Launches(); launches != 2 {
+		t.Errorf("%d browsers launched, want 2", launches)
+	}
+
+	// A request willing to wait is queued and launched once a slot frees up
+	queued := make(chan *models.Session, 1)
+	go func() {
+		resp, body := ts.do("POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-e2e", WaitTimeout: 10}, nil)
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("queued session: got %d: %s", resp.StatusCode, body)
+			queued <- nil
+			return
+		}
+		var sess models.Session
+		json.Unmarshal(body, &sess)
+		queued <- &sess
+	}()
+
+	var metrics models.Metrics
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ts.expect(http.StatusOK, "GET", "/v1/metrics", nil, &metrics)
+		if len(metrics.Projects) == 1 && metrics.Projects[0].QueueDepth == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("request never joined the queue: %+v", metrics.Projects)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+first.ID, nil, nil)
+
+	select {
+	case sess := <-queued:
+		if sess == nil {
+			t.FailNow()
+		}
+		if sess.Status != models.StatusRunning {
+			t.Errorf("queued session is %s, want %s", sess.Status, models.StatusRunning)
+		}
+		if sess.Queue == nil || sess.Queue.Position != 1 {
+			t.Errorf("queue info = %+v, want position 1", sess.Queue)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("queued session was not created after a slot freed up")
+	}
+
+	if running := ts.backend().Running(); running != 2 {
+		t.Errorf("%d browsers running, want 2", running)
+	}
+}
+
+func TestCancelQueuedSession(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 1)
+
+	first := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+
+	var queued models.Session
+	ts.expect(http.StatusAccepted, "POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-e2e", Async: true, WaitTimeout: 30}, &queued)
+	if queued.Status != models.StatusPending || queued.Queue == nil || queued.Queue.Position != 1 {
+		t.Fatalf("async session is %s with queue %+v, want PENDING at position 1", queued.Status, queued.Queue)
+	}
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+queued.ID, nil, nil)
+
+	cancelled := ts.getSession(queued.ID)
+	if cancelled.Status != models.StatusCompleted || cancelled.EndReason != models.EndReasonRequested || cancelled.EndedAt == nil {
+		t.Errorf("cancelled session = %s (%s), want COMPLETED (%s)", cancelled.Status, cancelled.EndReason, models.EndReasonRequested)
+	}
+	ts.expect(http.StatusBadRequest, "DELETE", "/v1/sessions/"+queued.ID, nil, nil)
+
+	var metrics models.Metrics
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ts.expect(http.StatusOK, "GET", "/v1/metrics", nil, &metrics)
+		if len(metrics.Projects) == 1 && metrics.Projects[0].QueueDepth == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cancelled session is still queued: %+v", metrics.Projects)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if metrics.Projects[0].QueueTimeouts != 0 {
+		t.Errorf("cancellation counted as %d queue timeouts", metrics.Projects[0].QueueTimeouts)
+	}
+
+	// The freed slot goes to new sessions, not the cancelled one
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+first.ID, nil, nil)
+	ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+	if launches := ts.backend().Launches(); launches != 2 {
+		t.Errorf("%d browsers launched, want 2", launches)
+	}
+	if status := ts.getSession(queued.ID).Status; status != models.StatusCompleted {
+		t.Errorf("cancelled session became %s", status)
+	}
+}
+
+func TestProjectUsage(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.expect(http.StatusNotFound, "GET", "/v1/projects/proj-missing/usage", nil, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/projects/proj-missing/usage?format=csv", nil, nil)
+
+	ts.createProject("proj-e2e", 1)
+	var report models.ProjectUsage
+	ts.expect(http.StatusOK, "GET", "/v1/projects/proj-e2e/usage", nil, &report)
+	if report.ProjectID != "proj-e2e" {
+		t.Errorf("usage report is for %q", report.ProjectID)
+	}
+
+	resp, _ := ts.do("GET", "/v1/projects/proj-e2e/usage?format=csv", nil, nil)
+	if want := `attachment; filename="usage-proj-e2e-day.csv"`; resp.Header.Get("Content-Disposition") != want {
+		t.Errorf("Content-Disposition = %q, want %q", resp.Header.Get("Content-Disposition"), want)
+	}
+}
+
+func TestProjectQuota(t *testing.T) {
+	ts := newTestServer(t, 100)
+
+	limit := models.SetQuotaRequest{MonthlyBrowserMinutes: 60}
+	ts.expect(http.StatusNotFound, "PUT", "/v1/projects/proj-missing/quota", limit, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/projects/proj-missing/quota", nil, nil)
+
+	ts.createProject("proj-e2e", 1)
+	var status models.QuotaStatus
+	ts.expect(http.StatusOK, "PUT", "/v1/projects/proj-e2e/quota", limit, &status)
+	if status.MonthlyBrowserMinutes != 60 {
+		t.Fatalf("quota = %+v, want 60 browser minutes", status)
+	}
+
+	// A project re-created with the same ID does not inherit the old quota
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/projects/proj-e2e", nil, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/projects/proj-e2e/quota", nil, nil)
+	ts.createProject("proj-e2e", 1)
+	ts.expect(http.StatusOK, "GET", "/v1/projects/proj-e2e/quota", nil, &status)
+	if status.MonthlyBrowserMinutes != 0 {
+		t.Errorf("re-created project has quota %+v", status)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	ts := newTestServer(t, 3)
+
+	project := http.Header{"X-Project-Id": {"proj-limited"}}
+	for i := 1; i <= 3; i++ {
+		resp, body := ts.do("GET", "/v1/sessions", nil, project)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: got %d: %s", i, resp.StatusCode, body)
+		}
+	}
+
+	resp, _ := ts.do("GET", "/v1/sessions", nil, project)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst: got %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q, want 0", remaining)
+	}
+
+	// Limits are per project, and the query parameter counts like the header
+	resp, _ = ts.do("GET", "/v1/sessions?projectId=proj-other", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("another project was throttled: got %d", resp.StatusCode)
+	}
+	resp, _ = ts.do("GET", "/v1/sessions?projectId=proj-limited", nil, nil)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("throttled project via query parameter: got %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+
+	// Endpoints outside the session API are not limited
+	resp, _ = ts.do("GET", "/v1/metrics", nil, project)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("metrics were throttled: got %d", resp.StatusCode)
+	}
+}
+
+func TestContextPersistence(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	var browserContext models.Context
+	ts.expect(http.StatusCreated, "POST", "/v1/contexts", models.CreateContextRequest{ProjectID: "proj-e2e"}, &browserContext)
+	ts.expect(http.StatusOK, "GET", "/v1/contexts/"+browserContext.ID, nil, nil)
+
+	// The first session logs in and leaves a cookie in its profile
+	first := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e", ContextID: browserContext.ID, KeepAlive: true})
+	firstProfile := ts.backend().Browser(first.ID).UserDataDir
+
+	conn := ts.dial(first)
+	result := call(t, conn, 1, "Storage.setCookies", map[string]interface{}{
+		"cookies": []map[string]string{{"name": "sid", "value": "abc123", "domain": "example.com"}},
+	})
+	if result.Error != nil {
+		t.Fatalf("Storage.setCookies failed: %s", result.Error.Message)
+	}
+	conn.Close()
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+first.ID, nil, nil)
+	if _, err := os.Stat(firstProfile); !os.IsNotExist(err) {
+		t.Fatalf("first session's profile outlived its browser: %v", err)
+	}
+
+	var saved models.Context
+	ts.expect(http.StatusOK, "GET", "/v1/contexts/"+browserContext.ID, nil, &saved)
+	if !saved.UpdatedAt.After(browserContext.UpdatedAt) {
+		t.Error("context was not updated when the session ended")
+	}
+
+	// A later session with the context starts from the saved profile
+	second := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e", ContextID: browserContext.ID, KeepAlive: true})
+	conn = ts.dial(second)
+	defer conn.Close()
+
+	result = call(t, conn, 1, "Storage.getCookies", nil)
+	var cookies struct {
+		Cookies []map[string]interface{} `json:"cookies"`
+	}
+	must(t, json.Unmarshal(result.Result, &cookies))
+	if len(cookies.Cookies) != 1 || cookies.Cookies[0]["value"] != "abc123" {
+		t.Fatalf("second session has cookies %v, want the saved sid cookie", cookies.Cookies)
+	}
+
+	var listed []models.Session
+	ts.expect(http.StatusOK, "GET", "/v1/sessions?contextId="+browserContext.ID, nil, &listed)
+	if len(listed) != 2 {
+		t.Errorf("%d sessions listed for the context, want 2", len(listed))
+	}
+
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/sessions/"+second.ID, nil, nil)
+	ts.expect(http.StatusNoContent, "DELETE", "/v1/contexts/"+browserContext.ID, nil, nil)
+	ts.expect(http.StatusNotFound, "GET", "/v1/contexts/"+browserContext.ID, nil, nil)
+	ts.expect(http.StatusBadRequest, "POST", "/v1/sessions", models.CreateSessionRequest{ProjectID: "proj-e2e", ContextID: browserContext.ID}, nil)
+}
+
+func TestDebugProxy(t *testing.T) {
+	ts := newTestServer(t, 100)
+	ts.createProject("proj-e2e", 5)
+
+	sess := ts.createSession(models.CreateSessionRequest{ProjectID: "proj-e2e"})
+
+	var debug map[string]string
+	ts.expect(http.StatusOK, "GET", "/v1/sessions/"+sess.ID+"/debug", nil, &debug)
+
+	conn := ts.dial(sess)
+
+	result := call(t, conn, 1, "Browser.getVersion", nil)
+	var version struct {
+		Product string `json:"product"`
+	}
+	must(t, json.Unmarshal(result.Result, &version))
+	if !strings.HasPrefix(version.Product, "HeadlessChrome/") {
+		t.Errorf("product = %q, want HeadlessChrome", version.Product)
+	}
+
+	if result := call(t, conn, 2, "Page.navigate", map[string]string{"url": "https://example.com/"}); result.Error != nil {
+		t.Fatalf("Page.navigate failed: %s", result.Error.Message)
+	}
+	result = call(t, conn, 3, "Target.getTargets", nil)
+	if !strings.Contains(string(result.Result), "https://example.com/") {
+		t.Errorf("targets %s do not include the navigated page", result.Result)
+	}
+
+	if result := call(t, conn, 4, "Bogus.method", nil); result.Error == nil {
+		t.Error("unknown CDP method succeeded")
+	}
+
+	// A client that reconnects within the grace period keeps the session
+	ts.sessions.SetDisconnectGrace(300 * time.Millisecond)
+	conn.Close()
+	time.Sleep(50 * time.Millisecond)
+	conn = ts.dial(sess)
+	time.Sleep(500 * time.Millisecond)
+	if status := ts.getSession(sess.ID).Status; status != models.StatusRunning {
+		t.Fatalf("session is %s after its client reconnected, want %s", status, models.StatusRunning)
+	}
+
+	// Without keepAlive the session ends once its last client stays away
+	conn.Close()
+	ended := ts.waitForStatus(sess.ID, models.StatusCompleted, 5*time.Second)
+	if ended.EndReason != models.EndReasonClientDisconnected {
+		t.Errorf("endReason = %s, want %s", ended.EndReason, models.EndReasonClientDisconnected)
+	}
+
+	// Ended sessions can no longer be attached to
+	_, resp, err := websocket.DefaultDialer.Dial(sess.ConnectURL, nil)
+	if err == nil {
+		t.Fatal("connected to an ended session")
+	}
+	if resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("connecting to an ended session: got %v, want status %d", resp, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/events"
+)
+
+// eventReplayTruncated is sent first on a stream resumed from a Last-Event-ID
+// whose following events are no longer buffered
+const eventReplayTruncated = "replay.truncated"
+
+// StreamEvents handles GET /v1/events as a Server-Sent Events stream
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	filter := events.Filter{
+		ProjectID: r.URL.Query().Get("projectId"),
+		SessionID: r.URL.Query().Get("sessionId"),
+	}
+
+	// Browsers send Last-Event-ID on reconnect; the query param helps curl users
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	var resumeFrom uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		resumeFrom = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The server-wide WriteTimeout would otherwise cut the stream off
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	sub, missed := h.sessionMgr.Events().Subscribe(filter, resumeFrom)
+	defer h.sessionMgr.Events().Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if sub.Truncated {
+		// Tell the client to resync state it can no longer replay. The event
+		// carries no id, so the client's Last-Event-ID is left alone.
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: {\"lastEventId\":%d}\n\n", eventReplayTruncated, resumeFrom); err != nil {
+			return
+		}
+	}
+	for _, e := range missed {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client resumes with Last-Event-ID
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				log.Printf("SSE write failed: %v", err)
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a single event in SSE wire format
+func writeEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -3,12 +3,18 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/admission"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
 	"github.com/shehryarbajwa/browserbase-mini/internal/session"
 	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
 )
@@ -34,14 +40,40 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.WaitTimeout > 0 && !req.Async {
+		// Queueing for a slot can outlast the server-wide WriteTimeout
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	}
+
 	session, err := h.sessionMgr.CreateSession(r.Context(), req)
+	var capacityErr *admission.CapacityError
+	if errors.As(err, &capacityErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(capacityErr.RetryAfter.Seconds())))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, quota.ErrExceeded) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, project.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	status := http.StatusCreated
+	if req.Async {
+		// Launch continues in the background; clients poll GET /v1/sessions/{id}
+		status = http.StatusAccepted
+		w.Header().Set("Location", "/v1/sessions/"+session.ID)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(session)
 }
 
@@ -56,24 +88,121 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Async sessions waiting for a slot report their live queue position
+	if session.Status == models.StatusPending {
+		if queue := h.sessionMgr.QueuePosition(session); queue != nil {
+			queued := *session
+			queued.Queue = queue
+			session = &queued
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(session)
 }
 
-// ListSessions handles GET /v1/sessions
+// ListSessions handles GET /v1/sessions. Filters: projectId, status, region,
+// contextId, startedAfter and startedBefore (RFC 3339), and metadata.<key>=<value>
+// for userMetadata pairs. sort (startedAt or expiresAt) and order (asc or desc)
+// set the ordering. With limit or cursor the response is a page envelope
+// carrying nextCursor; otherwise it is a plain array of every match. Sessions
+// evicted after their retention period are left out of lists.
 func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
-	projectID := r.URL.Query().Get("projectId")
-	statusStr := r.URL.Query().Get("status")
+	query := r.URL.Query()
 
-	var status models.SessionStatus
-	if statusStr != "" {
-		status = models.SessionStatus(statusStr)
+	opts := session.ListOptions{
+		ProjectID: query.Get("projectId"),
+		Status:    models.SessionStatus(query.Get("status")),
+		Region:    query.Get("region"),
+		ContextID: query.Get("contextId"),
 	}
 
-	sessions := h.sessionMgr.ListSessions(projectID, status)
+	for param, target := range map[string]*time.Time{"startedAfter": &opts.StartedAfter, "startedBefore": &opts.StartedBefore} {
+		if value := query.Get(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, param+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	for param, values := range query {
+		key, ok := strings.CutPrefix(param, "metadata.")
+		if !ok {
+			continue
+		}
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]string)
+		}
+		opts.Metadata[key] = values[0]
+	}
+
+	page := session.PageOptions{
+		SortBy: session.SortField(query.Get("sort")),
+		Cursor: query.Get("cursor"),
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		page.Descending = true
+	default:
+		http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+		return
+	}
+
+	paginated := query.Has("limit") || page.Cursor != ""
+	if paginated {
+		page.Limit = 50
+		if value := query.Get("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", session.MaxPageSize), http.StatusBadRequest)
+				return
+			}
+			page.Limit = limit
+		}
+	}
+
+	result, err := h.sessionMgr.ListSessionsPage(opts, page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if paginated {
+		json.NewEncoder(w).Encode(result)
+	} else {
+		json.NewEncoder(w).Encode(result.Data)
+	}
+}
+
+// UpdateSession handles PATCH /v1/sessions/{id}
+func (h *Handler) UpdateSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	session, err := h.sessionMgr.UpdateSession(id, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sessions)
+	json.NewEncoder(w).Encode(session)
 }
 
 // DeleteSession handles DELETE /v1/sessions/{id}
@@ -134,7 +263,7 @@ func (h *Handler) GetSessionScreenshot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Send screenshot command
-	result, err := conn.SendCommand(map[string]string{
+	result, err := conn.SendCommand(map[string]interface{}{
 		"action": "screenshot",
 	}, 10*time.Second)
 
@@ -201,7 +330,7 @@ func (h *Handler) NavigateSession(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🚀 Navigating session %s to %s", sessionID[:8], req.URL)
 
 	// Send navigate command
-	result, err := conn.SendCommand(map[string]string{
+	result, err := conn.SendCommand(map[string]interface{}{
 		"action": "navigate",
 		"url":    req.URL,
 	}, 35*time.Second)
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/artifacts"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// GetSessionLogs handles GET /v1/sessions/{id}/logs. Query parameters:
+// level (minimum severity), since and until (RFC 3339), and follow=true to
+// stream NDJSON for as long as the session is live.
+func (h *Handler) GetSessionLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := artifacts.LogFilter{MinLevel: query.Get("level")}
+	if filter.MinLevel != "" && !artifacts.ValidLogLevel(filter.MinLevel) {
+		http.Error(w, "level must be one of debug, info, warning, error", http.StatusBadRequest)
+		return
+	}
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, param+" must be an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	store := h.sessionMgr.Artifacts()
+
+	if query.Get("follow") != "true" {
+		entries, err := store.ReadLogs(id, filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+		return
+	}
+
+	h.followSessionLogs(w, r, id, filter)
+}
+
+// followSessionLogs writes stored entries as NDJSON and then streams new ones
+// until the session ends or the client goes away
+func (h *Handler) followSessionLogs(w http.ResponseWriter, r *http.Request, id string, filter artifacts.LogFilter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	backlog, live, stop, err := h.sessionMgr.Artifacts().FollowLogs(id, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer stop()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for _, entry := range backlog {
+		encoder.Encode(entry)
+	}
+	flusher.Flush()
+
+	// The session may already be over, or end without a bridge to close us
+	statusCheck := time.NewTicker(2 * time.Second)
+	defer statusCheck.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, ok := <-live:
+			if !ok {
+				return
+			}
+			if !filter.Matches(entry) {
+				continue
+			}
+			if err := encoder.Encode(entry); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-statusCheck.C:
+			if !isLive(h.sessionMgr.GetSession(id)) {
+				return
+			}
+		}
+	}
+}
+
+// isLive reports whether a session can still produce output
+func isLive(session *models.Session, err error) bool {
+	if err != nil {
+		return false
+	}
+	switch session.Status {
+	case models.StatusPending, models.StatusStarting, models.StatusRunning:
+		return true
+	}
+	return false
+}
+
+// GetSessionHAR handles GET /v1/sessions/{id}/har
+func (h *Handler) GetSessionHAR(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	har, err := h.sessionMgr.Artifacts().BuildHAR(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"session-%s.har\"", id))
+	json.NewEncoder(w).Encode(har)
+}
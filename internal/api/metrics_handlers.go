@@ -0,0 +1,12 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetMetrics handles GET /v1/metrics with per-project slot use and queue depth
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.sessionMgr.Metrics())
+}
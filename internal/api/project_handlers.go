@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// ProjectHandler holds dependencies for project registry HTTP handlers
+type ProjectHandler struct {
+	projectMgr *project.Manager
+	quotaMgr   *quota.Manager
+}
+
+// NewProjectHandler creates a new project HTTP handler
+func NewProjectHandler(projectMgr *project.Manager, quotaMgr *quota.Manager) *ProjectHandler {
+	return &ProjectHandler{
+		projectMgr: projectMgr,
+		quotaMgr:   quotaMgr,
+	}
+}
+
+// CreateProject handles POST /v1/projects
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.projectMgr.CreateProject(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(project)
+}
+
+// ListProjects handles GET /v1/projects
+func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.projectMgr.ListProjects())
+}
+
+// GetProject handles GET /v1/projects/{id}
+func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	project, err := h.projectMgr.GetProject(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(project)
+}
+
+// UpdateProject handles PATCH /v1/projects/{id}
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.projectMgr.UpdateProject(id, req)
+	if errors.Is(err, project.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteProject handles DELETE /v1/projects/{id}
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.projectMgr.DeleteProject(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := h.quotaMgr.DeleteQuota(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // SetupRoutes configures all HTTP routes
-func (h *Handler) SetupRoutes(contextHandler *ContextHandler, proxyServer *proxy.Server, rateLimiter *ratelimit.Limiter) *mux.Router {
+func (h *Handler) SetupRoutes(contextHandler *ContextHandler, projectHandler *ProjectHandler, webhookHandler *WebhookHandler, proxyServer *proxy.Server, rateLimiter *ratelimit.Limiter) *mux.Router {
 	r := mux.NewRouter()
 
 	// API v1 routes
@@ -23,11 +23,22 @@ func (h *Handler) SetupRoutes(contextHandler *ContextHandler, proxyServer *proxy
 	rateLimitedAPI.HandleFunc("/sessions", h.CreateSession).Methods("POST")
 	rateLimitedAPI.HandleFunc("/sessions", h.ListSessions).Methods("GET")
 	rateLimitedAPI.HandleFunc("/sessions/{id}", h.GetSession).Methods("GET")
+	rateLimitedAPI.HandleFunc("/sessions/{id}", h.UpdateSession).Methods("PATCH")
 	rateLimitedAPI.HandleFunc("/sessions/{id}", h.DeleteSession).Methods("DELETE")
 
 	// Screenshot endpoint (not rate limited - frequent polling)
 	api.HandleFunc("/sessions/{id}/screenshot", h.GetSessionScreenshot).Methods("GET")
 
+	// Resource stats endpoint (not rate limited - supports streaming)
+	api.HandleFunc("/sessions/{id}/stats", h.GetSessionStats).Methods("GET")
+
+	// Artifact endpoints (not rate limited - logs support streaming)
+	api.HandleFunc("/sessions/{id}/logs", h.GetSessionLogs).Methods("GET")
+	api.HandleFunc("/sessions/{id}/har", h.GetSessionHAR).Methods("GET")
+	api.HandleFunc("/sessions/{id}/downloads", h.ListDownloads).Methods("GET")
+	api.HandleFunc("/sessions/{id}/downloads/{name}", h.GetDownload).Methods("GET")
+	api.HandleFunc("/sessions/{id}/uploads", h.UploadFiles).Methods("POST")
+
 	// Debug endpoints (not rate limited)
 	api.HandleFunc("/sessions/{id}/debug", h.GetDebugURL).Methods("GET")
 	api.HandleFunc("/sessions/{id}/ws", func(w http.ResponseWriter, r *http.Request) {
@@ -36,12 +47,38 @@ func (h *Handler) SetupRoutes(contextHandler *ContextHandler, proxyServer *proxy
 		proxyServer.HandleDebugConnection(w, r, sessionID)
 	}).Methods("GET")
 	api.HandleFunc("/sessions/{id}/navigate", h.NavigateSession).Methods("POST", "OPTIONS")
+	api.HandleFunc("/sessions/{id}/set-input-files", h.SetInputFiles).Methods("POST")
+
+	// Load metrics (not rate limited - polled by monitoring)
+	api.HandleFunc("/metrics", h.GetMetrics).Methods("GET")
+
+	// Lifecycle event stream (not rate limited - long-lived connection)
+	api.HandleFunc("/events", h.StreamEvents).Methods("GET")
 
 	// Context endpoints (not rate limited)
 	api.HandleFunc("/contexts", contextHandler.CreateContext).Methods("POST")
 	api.HandleFunc("/contexts/{id}", contextHandler.GetContext).Methods("GET")
 	api.HandleFunc("/contexts/{id}", contextHandler.DeleteContext).Methods("DELETE")
 
+	// Project registry (not rate limited)
+	api.HandleFunc("/projects", projectHandler.CreateProject).Methods("POST")
+	api.HandleFunc("/projects", projectHandler.ListProjects).Methods("GET")
+	api.HandleFunc("/projects/{id}", projectHandler.GetProject).Methods("GET")
+	api.HandleFunc("/projects/{id}", projectHandler.UpdateProject).Methods("PATCH")
+	api.HandleFunc("/projects/{id}", projectHandler.DeleteProject).Methods("DELETE")
+
+	// Usage reporting and quotas (not rate limited)
+	api.HandleFunc("/projects/{id}/usage", h.GetProjectUsage).Methods("GET")
+	api.HandleFunc("/projects/{id}/quota", h.GetProjectQuota).Methods("GET")
+	api.HandleFunc("/projects/{id}/quota", h.SetProjectQuota).Methods("PUT")
+
+	// Webhook endpoints (not rate limited)
+	api.HandleFunc("/projects/{id}/webhooks", webhookHandler.CreateWebhook).Methods("POST")
+	api.HandleFunc("/projects/{id}/webhooks", webhookHandler.ListWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/webhooks/{id}/deliveries", webhookHandler.ListDeliveries).Methods("GET")
+	api.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}/redeliver", webhookHandler.RedeliverDelivery).Methods("POST")
+
 	// CORS middleware
 	r.Use(corsMiddleware)
 
@@ -52,8 +89,8 @@ func (h *Handler) SetupRoutes(contextHandler *ContextHandler, proxyServer *proxy
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
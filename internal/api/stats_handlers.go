@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// GetSessionStats handles GET /v1/sessions/{id}/stats with the CPU, memory,
+// network and block IO use of a running session's browser. stream=true
+// streams a reading a second as NDJSON until the session ends.
+func (h *Handler) GetSessionStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	session, err := h.sessionMgr.GetSession(id)
+	if err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.Status != models.StatusRunning {
+		http.Error(w, "Session is not running", http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("stream") == "true" {
+		h.streamSessionStats(w, r, session)
+		return
+	}
+
+	stats, err := h.sessionMgr.SessionStats(r.Context(), session)
+	if err != nil {
+		http.Error(w, err.Error(), statsErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// streamSessionStats writes readings as NDJSON until the browser stops or the
+// client goes away
+func (h *Handler) streamSessionStats(w http.ResponseWriter, r *http.Request, session *models.Session) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	encoder := json.NewEncoder(w)
+	started := false
+	err := h.sessionMgr.StreamSessionStats(r.Context(), session, func(stats *models.ResourceStats) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := encoder.Encode(stats); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+
+	// Once readings have gone out, errors can only end the stream
+	if err != nil && !started {
+		http.Error(w, err.Error(), statsErrorStatus(err))
+	}
+}
+
+// statsErrorStatus maps a failed stats read to a response status
+func statsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, browser.ErrStatsUnsupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, browser.ErrContainerNotFound):
+		// The browser stopped before the session caught up
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
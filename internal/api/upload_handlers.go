@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// maxUploadBytes bounds a single upload request
+const maxUploadBytes = 100 * 1024 * 1024
+
+// UploadFiles handles POST /v1/sessions/{id}/uploads. Every file part of the
+// multipart body is stored and its in-container path returned.
+func (h *Handler) UploadFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Large files take longer than the server read timeout allows
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected a multipart/form-data body", http.StatusBadRequest)
+		return
+	}
+
+	uploads := []*models.Upload{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid multipart body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		upload, err := h.sessionMgr.SaveUpload(id, filepath.Base(part.FileName()), part)
+		part.Close()
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		uploads = append(uploads, upload)
+	}
+
+	if len(uploads) == 0 {
+		http.Error(w, "No files in request", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("📤 Uploaded %d files to session %s", len(uploads), id[:8])
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(uploads)
+}
+
+// SetInputFiles handles POST /v1/sessions/{id}/set-input-files
+func (h *Handler) SetInputFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.SetInputFilesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.sessionMgr.GetSession(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := h.sessionMgr.SetInputFiles(id, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
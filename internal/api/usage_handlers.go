@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/session"
+	"github.com/shehryarbajwa/browserbase-mini/internal/usage"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// GetProjectUsage handles GET /v1/projects/{id}/usage. Query parameters:
+// granularity (day or month, default day), from and to (UTC dates,
+// 2006-01-02, inclusive), and format=csv for a spreadsheet-friendly export.
+func (h *Handler) GetProjectUsage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["id"]
+	query := r.URL.Query()
+
+	if _, err := h.sessionMgr.Projects().GetProject(projectID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	granularity := usage.Granularity(query.Get("granularity"))
+	if granularity == "" {
+		granularity = usage.Day
+	}
+
+	var from, to time.Time
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(param); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				http.Error(w, param+" must be a date like 2006-01-02", http.StatusBadRequest)
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	report, err := h.sessionMgr.Usage().Usage(projectID, granularity, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report.ActiveSessions = len(h.sessionMgr.ListSessions(session.ListOptions{
+		ProjectID: projectID,
+		Status:    models.StatusRunning,
+	}))
+
+	switch query.Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"usage-%s-%s.csv\"", projectID, granularity))
+		writeUsageCSV(w, report)
+	default:
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+	}
+}
+
+// GetProjectQuota handles GET /v1/projects/{id}/quota
+func (h *Handler) GetProjectQuota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["id"]
+
+	if _, err := h.sessionMgr.Projects().GetProject(projectID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.sessionMgr.QuotaStatus(projectID))
+}
+
+// SetProjectQuota handles PUT /v1/projects/{id}/quota. A zero limit removes it.
+func (h *Handler) SetProjectQuota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["id"]
+
+	if _, err := h.sessionMgr.Projects().GetProject(projectID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var req models.SetQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.sessionMgr.Quotas().SetQuota(projectID, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.sessionMgr.QuotaStatus(projectID))
+}
+
+// writeUsageCSV writes one row per period and region
+func writeUsageCSV(w http.ResponseWriter, report *models.ProjectUsage) {
+	out := csv.NewWriter(w)
+	out.Write([]string{"project_id", "period", "region", "browser_seconds", "browser_minutes", "sessions"})
+
+	for _, period := range report.Periods {
+		regions := make([]string, 0, len(period.Regions))
+		for region := range period.Regions {
+			regions = append(regions, region)
+		}
+		sort.Strings(regions)
+
+		for _, region := range regions {
+			usage := period.Regions[region]
+			out.Write([]string{
+				report.ProjectID,
+				period.Period,
+				region,
+				strconv.FormatInt(usage.BrowserSeconds, 10),
+				strconv.FormatInt(usage.BrowserMinutes, 10),
+				strconv.Itoa(usage.Sessions),
+			})
+		}
+	}
+
+	out.Flush()
+}
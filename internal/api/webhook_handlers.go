@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/shehryarbajwa/browserbase-mini/internal/webhook"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// WebhookHandler holds dependencies for webhook HTTP handlers
+type WebhookHandler struct {
+	webhookMgr *webhook.Manager
+}
+
+// NewWebhookHandler creates a new webhook HTTP handler
+func NewWebhookHandler(webhookMgr *webhook.Manager) *WebhookHandler {
+	return &WebhookHandler{
+		webhookMgr: webhookMgr,
+	}
+}
+
+// CreateWebhook handles POST /v1/projects/{id}/webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["id"]
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhookMgr.CreateWebhook(projectID, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// ListWebhooks handles GET /v1/projects/{id}/webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["id"]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.webhookMgr.ListWebhooks(projectID))
+}
+
+// DeleteWebhook handles DELETE /v1/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.webhookMgr.DeleteWebhook(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /v1/webhooks/{id}/deliveries
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := h.webhookMgr.GetWebhook(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	status := models.DeliveryStatus(r.URL.Query().Get("status"))
+	deliveries, err := h.webhookMgr.ListDeliveries(id, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// RedeliverDelivery handles POST /v1/webhooks/{id}/deliveries/{deliveryId}/redeliver
+func (h *WebhookHandler) RedeliverDelivery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	deliveryID := vars["deliveryId"]
+
+	delivery, err := h.webhookMgr.Redeliver(id, deliveryID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
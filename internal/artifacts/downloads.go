@@ -0,0 +1,149 @@
+package artifacts
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+const downloadsDir = "downloads"
+
+// partialSuffix marks files Chrome is still writing
+const partialSuffix = ".crdownload"
+
+// DownloadDir returns the host directory a session's browser downloads into,
+// creating it
+func (s *Store) DownloadDir(sessionID string) (string, error) {
+	dir := filepath.Join(s.root, filepath.Base(sessionID), downloadsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	// Chrome runs as an unprivileged user inside the container
+	if err := os.Chmod(dir, 0777); err != nil {
+		return "", fmt.Errorf("failed to open up download directory: %w", err)
+	}
+	return dir, nil
+}
+
+// ListDownloads returns a session's completed downloads sorted by name
+func (s *Store) ListDownloads(sessionID string) ([]models.Download, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.Base(sessionID), downloadsDir))
+	if os.IsNotExist(err) {
+		return []models.Download{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list downloads: %w", err)
+	}
+
+	downloads := []models.Download{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), partialSuffix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		downloads = append(downloads, models.Download{
+			Name:       entry.Name(),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(downloads, func(i, j int) bool {
+		return downloads[i].Name < downloads[j].Name
+	})
+	return downloads, nil
+}
+
+// OpenDownload opens a single completed download for reading
+func (s *Store) OpenDownload(sessionID, name string) (*os.File, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasSuffix(name, partialSuffix) {
+		return nil, fmt.Errorf("download not found")
+	}
+
+	file, err := os.Open(filepath.Join(s.root, filepath.Base(sessionID), downloadsDir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("download not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download: %w", err)
+	}
+
+	if info, err := file.Stat(); err != nil || !info.Mode().IsRegular() {
+		file.Close()
+		return nil, fmt.Errorf("download not found")
+	}
+	return file, nil
+}
+
+// WriteDownloadsZip streams all of a session's completed downloads as a zip
+func (s *Store) WriteDownloadsZip(sessionID string, w io.Writer) error {
+	downloads, err := s.ListDownloads(sessionID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, download := range downloads {
+		if err := s.addToZip(archive, sessionID, download); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// addToZip copies one download into the archive
+func (s *Store) addToZip(archive *zip.Writer, sessionID string, download models.Download) error {
+	file, err := s.OpenDownload(sessionID, download.Name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     download.Name,
+		Method:   zip.Deflate,
+		Modified: download.ModifiedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+// SessionsWithDownloads returns the IDs of sessions that have download files
+func (s *Store) SessionsWithDownloads() ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact directory: %w", err)
+	}
+
+	var sessionIDs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.root, entry.Name(), downloadsDir))
+		if err == nil && len(files) > 0 {
+			sessionIDs = append(sessionIDs, entry.Name())
+		}
+	}
+	return sessionIDs, nil
+}
+
+// DeleteDownloads removes every file a session downloaded
+func (s *Store) DeleteDownloads(sessionID string) error {
+	return os.RemoveAll(filepath.Join(s.root, filepath.Base(sessionID), downloadsDir))
+}
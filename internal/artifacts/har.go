@@ -0,0 +1,323 @@
+package artifacts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const networkLogFile = "network.ndjson"
+
+// NetworkRecord is one completed request as reported by the Puppeteer bridge
+// from CDP Network domain events
+type NetworkRecord struct {
+	StartedDateTime time.Time         `json:"startedDateTime"`
+	Time            float64           `json:"time"` // total milliseconds
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	HTTPVersion     string            `json:"httpVersion"`
+	RequestHeaders  map[string]string `json:"requestHeaders"`
+	PostData        string            `json:"postData,omitempty"`
+	Status          int               `json:"status"`
+	StatusText      string            `json:"statusText"`
+	ResponseHeaders map[string]string `json:"responseHeaders"`
+	MimeType        string            `json:"mimeType"`
+	BodySize        int64             `json:"bodySize"`
+	Timing          *ResourceTiming   `json:"timing,omitempty"`
+	RemoteIPAddress string            `json:"remoteIPAddress,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	Base64Encoded   bool              `json:"base64Encoded,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// ResourceTiming mirrors CDP Network.ResourceTiming; offsets are milliseconds
+// relative to the start of the request and -1 when not applicable
+type ResourceTiming struct {
+	DNSStart          float64 `json:"dnsStart"`
+	DNSEnd            float64 `json:"dnsEnd"`
+	ConnectStart      float64 `json:"connectStart"`
+	ConnectEnd        float64 `json:"connectEnd"`
+	SSLStart          float64 `json:"sslStart"`
+	SSLEnd            float64 `json:"sslEnd"`
+	SendStart         float64 `json:"sendStart"`
+	SendEnd           float64 `json:"sendEnd"`
+	ReceiveHeadersEnd float64 `json:"receiveHeadersEnd"`
+}
+
+// HAR 1.2 document types, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Pages   []HARPage  `json:"pages"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+}
+
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// AppendNetwork stores a completed request in the session's network log
+func (s *Store) AppendNetwork(sessionID string, record NetworkRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A truncated network log still yields a valid HAR, just a shorter one
+	s.appendBounded(sessionID, networkLogFile, data, []byte("{}"), s.limits.NetworkLogBytes)
+}
+
+// BuildHAR assembles the session's network log into a HAR 1.2 document
+func (s *Store) BuildHAR(sessionID string) (*HAR, error) {
+	har := &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "browserbase-mini", Version: "1.0"},
+			Pages:   []HARPage{},
+			Entries: []HAREntry{},
+		},
+	}
+
+	file, err := os.Open(filepath.Join(s.root, filepath.Base(sessionID), networkLogFile))
+	if os.IsNotExist(err) {
+		return har, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open network log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record NetworkRecord
+		if json.Unmarshal(scanner.Bytes(), &record) != nil || record.URL == "" {
+			continue
+		}
+		har.Log.Entries = append(har.Log.Entries, harEntry(record))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read network log: %w", err)
+	}
+
+	sort.SliceStable(har.Log.Entries, func(i, j int) bool {
+		return har.Log.Entries[i].StartedDateTime.Before(har.Log.Entries[j].StartedDateTime)
+	})
+	return har, nil
+}
+
+// harEntry converts a bridge record into a HAR entry
+func harEntry(r NetworkRecord) HAREntry {
+	entry := HAREntry{
+		StartedDateTime: r.StartedDateTime,
+		Time:            r.Time,
+		ServerIPAddress: r.RemoteIPAddress,
+		Comment:         r.Error,
+		Request: HARRequest{
+			Method:      r.Method,
+			URL:         r.URL,
+			HTTPVersion: r.HTTPVersion,
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(r.RequestHeaders),
+			QueryString: harQuery(r.URL),
+			HeadersSize: -1,
+			BodySize:    len(r.PostData),
+		},
+		Response: HARResponse{
+			Status:      r.Status,
+			StatusText:  r.StatusText,
+			HTTPVersion: r.HTTPVersion,
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(r.ResponseHeaders),
+			Content: HARContent{
+				Size:     r.BodySize,
+				MimeType: r.MimeType,
+				Text:     r.Body,
+			},
+			RedirectURL: headerValue(r.ResponseHeaders, "Location"),
+			HeadersSize: -1,
+			BodySize:    r.BodySize,
+		},
+		Timings: harTimings(r.Timing, r.Time),
+	}
+
+	if r.Base64Encoded {
+		entry.Response.Content.Encoding = "base64"
+	}
+	if r.PostData != "" {
+		entry.Request.PostData = &HARPostData{
+			MimeType: headerValue(r.RequestHeaders, "Content-Type"),
+			Text:     r.PostData,
+		}
+	}
+
+	return entry
+}
+
+// harTimings splits the total time into HAR phases using CDP resource timing
+func harTimings(t *ResourceTiming, total float64) HARTimings {
+	if t == nil {
+		// Served from cache or failed before a response: all time is waiting
+		return HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: total}
+	}
+
+	phase := func(start, end float64) float64 {
+		if start < 0 || end < 0 {
+			return -1
+		}
+		return end - start
+	}
+
+	timings := HARTimings{
+		Blocked: -1,
+		DNS:     phase(t.DNSStart, t.DNSEnd),
+		Connect: phase(t.ConnectStart, t.ConnectEnd),
+		SSL:     phase(t.SSLStart, t.SSLEnd),
+		Send:    phase(t.SendStart, t.SendEnd),
+		Wait:    phase(t.SendEnd, t.ReceiveHeadersEnd),
+		Receive: total - t.ReceiveHeadersEnd,
+	}
+	if t.DNSStart > 0 {
+		timings.Blocked = t.DNSStart
+	}
+	if timings.Receive < 0 {
+		timings.Receive = 0
+	}
+
+	return timings
+}
+
+// harHeaders converts a CDP header map into sorted HAR name/value pairs
+func harHeaders(headers map[string]string) []HARNameValue {
+	pairs := []HARNameValue{}
+	for name, value := range headers {
+		// CDP folds repeated headers into one value separated by newlines
+		for _, v := range strings.Split(value, "\n") {
+			pairs = append(pairs, HARNameValue{Name: name, Value: v})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Name < pairs[j].Name
+	})
+	return pairs
+}
+
+// harQuery extracts the query string parameters of a URL
+func harQuery(rawURL string) []HARNameValue {
+	pairs := []HARNameValue{}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return pairs
+	}
+
+	for name, values := range parsed.Query() {
+		for _, v := range values {
+			pairs = append(pairs, HARNameValue{Name: name, Value: v})
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Name < pairs[j].Name
+	})
+	return pairs
+}
+
+// headerValue looks up a header case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package artifacts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+const consoleLogFile = "console.ndjson"
+
+// levelRank orders log levels so filters can ask for a minimum severity
+var levelRank = map[string]int{
+	"debug":   0,
+	"info":    1,
+	"warning": 2,
+	"error":   3,
+}
+
+// LogFilter narrows a log query; zero values match everything
+type LogFilter struct {
+	MinLevel string
+	Since    time.Time
+	Until    time.Time
+}
+
+// Matches reports whether an entry passes the filter
+func (f LogFilter) Matches(entry models.LogEntry) bool {
+	if f.MinLevel != "" && levelRank[entry.Level] < levelRank[f.MinLevel] {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ValidLogLevel reports whether level is one of the normalized levels
+func ValidLogLevel(level string) bool {
+	_, ok := levelRank[level]
+	return ok
+}
+
+// AppendLog writes an entry to the session's console log and hands it to
+// live followers. Past the size cap entries are only streamed, not stored.
+func (s *Store) AppendLog(sessionID string, entry models.LogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.logSubs[sessionID] {
+		select {
+		case ch <- entry:
+		default:
+			// Slow followers miss entries rather than stall the bridge
+		}
+	}
+
+	marker, _ := json.Marshal(models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "warning",
+		Source:    "browserbase",
+		Text:      fmt.Sprintf("console log truncated at %d bytes", s.limits.ConsoleLogBytes),
+	})
+	s.appendBounded(sessionID, consoleLogFile, data, marker, s.limits.ConsoleLogBytes)
+}
+
+// ReadLogs returns the stored entries of a session that match the filter
+func (s *Store) ReadLogs(sessionID string, filter LogFilter) ([]models.LogEntry, error) {
+	return s.readLogs(sessionID, filter, -1)
+}
+
+// readLogs reads the matching entries in the first size bytes of a session's
+// console log, or in all of it when size is negative
+func (s *Store) readLogs(sessionID string, filter LogFilter, size int64) ([]models.LogEntry, error) {
+	entries := []models.LogEntry{}
+
+	file, err := os.Open(s.logPath(sessionID))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open console log: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if size >= 0 {
+		reader = io.LimitReader(file, size)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry models.LogEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// FollowLogs returns the stored entries of a live session that match the
+// filter and subscribes to every entry appended after them. The log's size is
+// taken together with the subscription, so no entry is both read and
+// streamed or lost in between. The channel is unfiltered and closed when the
+// session ends; call the returned function to stop early.
+func (s *Store) FollowLogs(sessionID string, filter LogFilter) ([]models.LogEntry, <-chan models.LogEntry, func(), error) {
+	ch := make(chan models.LogEntry, 256)
+
+	s.mu.Lock()
+	if s.logSubs[sessionID] == nil {
+		s.logSubs[sessionID] = make(map[chan models.LogEntry]bool)
+	}
+	s.logSubs[sessionID][ch] = true
+	var size int64
+	if info, err := os.Stat(s.logPath(sessionID)); err == nil {
+		size = info.Size()
+	}
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.logSubs[sessionID][ch] {
+			delete(s.logSubs[sessionID], ch)
+			close(ch)
+		}
+	}
+
+	backlog, err := s.readLogs(sessionID, filter, size)
+	if err != nil {
+		stop()
+		return nil, nil, nil, err
+	}
+	return backlog, ch, stop, nil
+}
+
+func (s *Store) logPath(sessionID string) string {
+	return filepath.Join(s.root, filepath.Base(sessionID), consoleLogFile)
+}
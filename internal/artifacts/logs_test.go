@@ -0,0 +1,45 @@
+package artifacts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+func TestFollowLogsSplitsBacklogFromLive(t *testing.T) {
+	s, err := NewStore(t.TempDir(), Limits{ConsoleLogBytes: 1 << 20, NetworkLogBytes: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const id = "session-1"
+	now := time.Now()
+
+	s.AppendLog(id, models.LogEntry{Timestamp: now, Level: "info", Text: "stored"})
+
+	backlog, live, stop, err := s.FollowLogs(id, LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// Pages report their own clocks, so later entries can be stamped earlier
+	s.AppendLog(id, models.LogEntry{Timestamp: now.Add(-time.Minute), Level: "info", Text: "late"})
+
+	if len(backlog) != 1 || backlog[0].Text != "stored" {
+		t.Fatalf("backlog = %+v, want only the stored entry", backlog)
+	}
+	select {
+	case entry := <-live:
+		if entry.Text != "late" {
+			t.Errorf("streamed %q, want the late entry", entry.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the late entry was not streamed")
+	}
+	select {
+	case entry := <-live:
+		t.Errorf("streamed %q twice", entry.Text)
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+package artifacts
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// Limits bounds how much each session may write to its on-disk logs
+type Limits struct {
+	ConsoleLogBytes int64
+	NetworkLogBytes int64
+}
+
+// Store keeps per-session files that must outlive the browser container,
+// one directory per session under the store root
+type Store struct {
+	root   string
+	limits Limits
+
+	mu       sync.Mutex
+	logSizes map[string]int64                         // "sessionID/file" -> bytes written
+	logSubs  map[string]map[chan models.LogEntry]bool // sessionID -> live log followers
+}
+
+// NewStore creates an artifact store rooted at root
+func NewStore(root string, limits Limits) (*Store, error) {
+	// Session directories are bind-mounted into containers, which needs absolute paths
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	return &Store{
+		root:     root,
+		limits:   limits,
+		logSizes: make(map[string]int64),
+		logSubs:  make(map[string]map[chan models.LogEntry]bool),
+	}, nil
+}
+
+// SessionDir returns the artifact directory of a session, creating it
+func (s *Store) SessionDir(sessionID string) (string, error) {
+	dir := filepath.Join(s.root, filepath.Base(sessionID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create session artifact directory: %w", err)
+	}
+	return dir, nil
+}
+
+// CloseSession ends any live followers once a session stops producing output
+func (s *Store) CloseSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.logSubs[sessionID] {
+		close(ch)
+	}
+	delete(s.logSubs, sessionID)
+	delete(s.logSizes, sessionID+"/"+consoleLogFile)
+	delete(s.logSizes, sessionID+"/"+networkLogFile)
+}
+
+// appendBounded appends one NDJSON line to a session file unless that would
+// push it past limit. The first line that does not fit is replaced by marker
+// so readers can tell the file was cut short. Callers hold s.mu.
+func (s *Store) appendBounded(sessionID, name string, line, marker []byte, limit int64) {
+	path := filepath.Join(s.root, filepath.Base(sessionID), name)
+	key := sessionID + "/" + name
+
+	size, known := s.logSizes[key]
+	if !known {
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		}
+	}
+	if size >= limit {
+		return
+	}
+
+	if size+int64(len(line))+1 > limit {
+		line = marker
+		s.logSizes[key] = limit
+	} else {
+		s.logSizes[key] = size + int64(len(line)) + 1
+	}
+
+	if err := appendLine(path, line); err != nil {
+		log.Printf("⚠️ Failed to write %s for %s: %v", name, sessionID[:8], err)
+	}
+}
+
+// appendLine appends one line to a file, creating its directory
+func appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,72 @@
+package artifacts
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const uploadsDir = "uploads"
+
+// UploadDir returns the host directory holding files uploaded for a session,
+// creating it
+func (s *Store) UploadDir(sessionID string) (string, error) {
+	dir := filepath.Join(s.root, filepath.Base(sessionID), uploadsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	return dir, nil
+}
+
+// SaveUpload writes an uploaded file into the session's upload directory,
+// replacing any earlier upload with the same name. It returns the bytes written.
+func (s *Store) SaveUpload(sessionID, name string, r io.Reader) (int64, error) {
+	if !validFileName(name) {
+		return 0, fmt.Errorf("invalid file name %q", name)
+	}
+
+	dir, err := s.UploadDir(sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write under a temporary name so the browser never sees a partial file
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write upload: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write upload: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return 0, fmt.Errorf("failed to write upload: %w", err)
+	}
+	return size, nil
+}
+
+// HasUpload reports whether a file of this name was uploaded for the session
+func (s *Store) HasUpload(sessionID, name string) bool {
+	if !validFileName(name) {
+		return false
+	}
+
+	info, err := os.Stat(filepath.Join(s.root, filepath.Base(sessionID), uploadsDir, name))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// validFileName rejects names that could escape a session directory or
+// collide with in-progress temporary files
+func validFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && name == filepath.Base(name) && name[0] != '.'
+}
@@ -0,0 +1,58 @@
+package browser
+
+import (
+	"context"
+	"errors"
+)
+
+// Backend runs the browsers behind sessions in one region. Pool runs each
+// browser in a Docker container; LocalBackend runs local Chromium processes.
+type Backend interface {
+	// Launch starts a browser and waits until it accepts CDP connections
+	Launch(ctx context.Context, opts LaunchBrowserOptions) (*BrowserInstance, error)
+	// Stop shuts a browser down and releases its resources
+	Stop(ctx context.Context, containerID string) error
+	// IsHealthy reports whether a browser is still running
+	IsHealthy(ctx context.Context, containerID string) bool
+	// InspectState reports whether a browser is running and, if not, how it
+	// exited; unknown browsers yield ErrContainerNotFound
+	InspectState(ctx context.Context, containerID string) (*ContainerState, error)
+	// EnsureImage makes sure the browser can be launched, pulling or
+	// locating whatever it runs from
+	EnsureImage(ctx context.Context) error
+	// ListManagedContainers returns every browser this backend started,
+	// including ones that have exited
+	ListManagedContainers(ctx context.Context) ([]ManagedContainer, error)
+	// Close releases the backend's own resources
+	Close() error
+}
+
+// WarmPooler is implemented by backends that can keep browsers started ahead
+// of demand
+type WarmPooler interface {
+	StartWarmPool(ctx context.Context, cfg WarmPoolConfig) error
+	DrainWarmPool()
+	WarmPoolStats() (stats WarmPoolStats, ok bool)
+}
+
+// ErrStatsUnsupported is returned for browsers whose backend cannot report
+// resource usage
+var ErrStatsUnsupported = errors.New("browser backend does not report resource stats")
+
+// StatsReader is implemented by backends that can report a browser's
+// resource usage
+type StatsReader interface {
+	// Stats takes one reading of a browser's resource usage
+	Stats(ctx context.Context, containerID string) (*Stats, error)
+	// StreamStats calls fn with a reading about once a second until ctx is
+	// cancelled, the browser stops or fn returns an error
+	StreamStats(ctx context.Context, containerID string, fn func(*Stats) error) error
+}
+
+// Compile-time checks that the backends satisfy the interfaces
+var (
+	_ Backend     = (*Pool)(nil)
+	_ WarmPooler  = (*Pool)(nil)
+	_ StatsReader = (*Pool)(nil)
+	_ Backend     = (*LocalBackend)(nil)
+)
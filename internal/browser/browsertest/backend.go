@@ -0,0 +1,565 @@
+// Package browsertest provides an in-memory browser backend for tests that
+// exercise the API without Docker or a Chromium binary.
+package browsertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+)
+
+// cookieFile is where a fake browser keeps its cookies inside the profile, so
+// they travel with saved contexts like a real profile's cookie store
+const cookieFile = "cookies.json"
+
+// statsInterval is how often StreamStats emits a reading; Docker's stream
+// emits one a second, which is too slow for tests
+const statsInterval = 50 * time.Millisecond
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// Backend is a browser.Backend whose browsers are HTTP servers on loopback
+// ports. Each serves /json/version and a CDP WebSocket that answers a small
+// set of methods. Stop discards the browser's profile, as a container's disk
+// would be, so state only survives a session through a saved context.
+type Backend struct {
+	region   string
+	mu       sync.Mutex
+	browsers map[string]*Browser // ID -> browser
+	launches int
+}
+
+// Compile-time checks that the fake satisfies the backend interfaces
+var (
+	_ browser.Backend     = (*Backend)(nil)
+	_ browser.StatsReader = (*Backend)(nil)
+)
+
+// Browser is one fake browser started by the backend
+type Browser struct {
+	ID          string
+	SessionID   string
+	UserDataDir string
+	StartedAt   time.Time
+	Resources   browser.ResourceLimits // the limits it was launched with
+
+	server     *httptest.Server
+	mu         sync.Mutex
+	conns      map[*cdpConn]bool // open CDP connections
+	pages      []*page           // open tabs, oldest first
+	inputFiles []string          // last files set on a page's file input
+	exitCode   *int              // set once the browser has exited
+	oomKilled  bool
+	stats      browser.Stats // reported by Stats and StreamStats
+}
+
+// NewBackend creates an empty fake backend for region
+func NewBackend(region string) *Backend {
+	return &Backend{
+		region:   region,
+		browsers: make(map[string]*Browser),
+	}
+}
+
+// Launch starts a fake browser for the session
+func (b *Backend) Launch(ctx context.Context, opts browser.LaunchBrowserOptions) (*browser.BrowserInstance, error) {
+	userDataDir := opts.UserDataDir
+	if userDataDir == "" {
+		userDataDir = filepath.Join(os.TempDir(), "browsertest", opts.SessionID)
+	}
+	if err := os.MkdirAll(userDataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create user data directory: %w", err)
+	}
+
+	fake := &Browser{
+		ID:          "fake-" + uuid.New().String(),
+		SessionID:   opts.SessionID,
+		UserDataDir: userDataDir,
+		StartedAt:   time.Now(),
+		Resources:   opts.Resources,
+		conns:       make(map[*cdpConn]bool),
+	}
+	// The first tab keeps the browser's ID, as debugger URLs assume
+	fake.pages = []*page{{targetID: fake.ID, tabID: "tab-" + fake.ID, url: "about:blank"}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", fake.serveVersion)
+	mux.HandleFunc("/devtools/browser/", fake.serveCDP)
+	fake.server = httptest.NewServer(mux)
+
+	b.mu.Lock()
+	b.browsers[fake.ID] = fake
+	b.launches++
+	b.mu.Unlock()
+
+	return &browser.BrowserInstance{
+		ContainerID:  fake.ID,
+		SessionID:    opts.SessionID,
+		ConnectURL:   fake.debuggerURL(),
+		Region:       b.region,
+		Port:         strconv.Itoa(fake.server.Listener.Addr().(*net.TCPAddr).Port),
+		UserDataDir:  userDataDir,
+		DownloadPath: opts.DownloadDir,
+		UploadPath:   opts.UploadDir,
+	}, nil
+}
+
+// Stop shuts a browser's server down and discards its profile
+func (b *Backend) Stop(ctx context.Context, containerID string) error {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	delete(b.browsers, containerID)
+	b.mu.Unlock()
+
+	if !ok {
+		return browser.ErrContainerNotFound
+	}
+
+	fake.exit(0, false)
+	return os.RemoveAll(fake.UserDataDir)
+}
+
+// IsHealthy reports whether the browser is still running
+func (b *Backend) IsHealthy(ctx context.Context, containerID string) bool {
+	state, err := b.InspectState(ctx, containerID)
+	return err == nil && state.Running
+}
+
+// InspectState reports whether a browser is running and, if not, its exit code
+func (b *Backend) InspectState(ctx context.Context, containerID string) (*browser.ContainerState, error) {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return nil, browser.ErrContainerNotFound
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if fake.exitCode != nil {
+		return &browser.ContainerState{ExitCode: *fake.exitCode, OOMKilled: fake.oomKilled}, nil
+	}
+	return &browser.ContainerState{Running: true}, nil
+}
+
+// EnsureImage has nothing to fetch
+func (b *Backend) EnsureImage(ctx context.Context) error {
+	return nil
+}
+
+// ListManagedContainers returns the browsers the backend has not stopped
+func (b *Backend) ListManagedContainers(ctx context.Context) ([]browser.ManagedContainer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	managed := make([]browser.ManagedContainer, 0, len(b.browsers))
+	for _, fake := range b.browsers {
+		fake.mu.Lock()
+		running := fake.exitCode == nil
+		fake.mu.Unlock()
+
+		managed = append(managed, browser.ManagedContainer{
+			ContainerID: fake.ID,
+			SessionID:   fake.SessionID,
+			Region:      b.region,
+			Running:     running,
+			CreatedAt:   fake.StartedAt,
+		})
+	}
+	return managed, nil
+}
+
+// Close stops every browser still running
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	browsers := b.browsers
+	b.browsers = make(map[string]*Browser)
+	b.mu.Unlock()
+
+	for _, fake := range browsers {
+		fake.exit(0, false)
+		os.RemoveAll(fake.UserDataDir)
+	}
+	return nil
+}
+
+// Stats returns the reading last set with SetStats
+func (b *Backend) Stats(ctx context.Context, containerID string) (*browser.Stats, error) {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return nil, browser.ErrContainerNotFound
+	}
+
+	stats, running := fake.reading()
+	if !running {
+		return nil, fmt.Errorf("browser %s is not running", containerID)
+	}
+	return stats, nil
+}
+
+// StreamStats emits the browser's reading every statsInterval until ctx is
+// cancelled or the browser exits
+func (b *Backend) StreamStats(ctx context.Context, containerID string, fn func(*browser.Stats) error) error {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return browser.ErrContainerNotFound
+	}
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for {
+		stats, running := fake.reading()
+		if !running {
+			return nil
+		}
+		if err := fn(stats); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Browser returns the running browser of a session, or nil
+func (b *Backend) Browser(sessionID string) *Browser {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, fake := range b.browsers {
+		if fake.SessionID == sessionID {
+			return fake
+		}
+	}
+	return nil
+}
+
+// Running returns how many browsers have been launched and not stopped
+func (b *Backend) Running() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.browsers)
+}
+
+// Launches returns how many browsers the backend has started
+func (b *Backend) Launches() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.launches
+}
+
+// Crash makes a browser exit with exitCode while the backend still tracks
+// it, as a container does when Chrome dies
+func (b *Backend) Crash(containerID string, exitCode int) error {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return browser.ErrContainerNotFound
+	}
+
+	fake.exit(exitCode, false)
+	return nil
+}
+
+// OOMKill makes a browser exit as the kernel's OOM killer leaves it
+func (b *Backend) OOMKill(containerID string) error {
+	b.mu.Lock()
+	fake, ok := b.browsers[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return browser.ErrContainerNotFound
+	}
+
+	fake.exit(137, true)
+	return nil
+}
+
+// SetStats sets the resource usage the browser reports from now on
+func (fake *Browser) SetStats(stats browser.Stats) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.stats = stats
+}
+
+// reading returns the browser's current stats, stamped now, and whether it
+// is still running
+func (fake *Browser) reading() (*browser.Stats, bool) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	stats := fake.stats
+	stats.Time = time.Now()
+	return &stats, fake.exitCode == nil
+}
+
+// exit closes the browser's server and drops its CDP connections
+func (fake *Browser) exit(exitCode int, oomKilled bool) {
+	fake.mu.Lock()
+	if fake.exitCode != nil {
+		fake.mu.Unlock()
+		return
+	}
+	fake.exitCode = &exitCode
+	fake.oomKilled = oomKilled
+	conns := fake.conns
+	fake.conns = nil
+	fake.mu.Unlock()
+
+	// Upgraded connections are no longer tracked by the server
+	for conn := range conns {
+		conn.ws.Close()
+	}
+	fake.server.Close()
+}
+
+// debuggerURL is the browser-level CDP endpoint, as Chromium reports it
+func (fake *Browser) debuggerURL() string {
+	return "ws" + strings.TrimPrefix(fake.server.URL, "http") + "/devtools/browser/" + strings.TrimPrefix(fake.ID, "fake-")
+}
+
+// serveVersion answers /json/version
+func (fake *Browser) serveVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"Browser":              "HeadlessChrome/0.0.0 (browsertest)",
+		"Protocol-Version":     "1.3",
+		"User-Agent":           "Mozilla/5.0 HeadlessChrome/0.0.0 (browsertest)",
+		"webSocketDebuggerUrl": fake.debuggerURL(),
+	})
+}
+
+// handle runs a browser-level command the fake knows outside of targets and
+// sessions. Page.navigate without a session drives the first tab.
+func (fake *Browser) handle(req cdpRequest) (interface{}, error) {
+	switch req.Method {
+	case "Browser.getVersion":
+		return map[string]string{
+			"protocolVersion": "1.3",
+			"product":         "HeadlessChrome/0.0.0",
+			"userAgent":       "Mozilla/5.0 HeadlessChrome/0.0.0 (browsertest)",
+			"jsVersion":       "0.0",
+		}, nil
+
+	case "Target.getTargets":
+		infos := []map[string]interface{}{}
+		for _, p := range fake.openPages() {
+			infos = append(infos, fake.pageInfo(p, false))
+		}
+		return map[string]interface{}{"targetInfos": infos}, nil
+
+	case "Page.navigate":
+		var params struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.URL == "" {
+			return nil, fmt.Errorf("invalid parameters: url is required")
+		}
+		pages := fake.openPages()
+		if len(pages) == 0 {
+			return nil, fmt.Errorf("no page to navigate")
+		}
+		fake.navigate(pages[0], params.URL)
+		return map[string]string{"frameId": pages[0].targetID, "loaderId": uuid.New().String()}, nil
+
+	case "Storage.getCookies":
+		cookies, err := fake.readCookies()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"cookies": cookies}, nil
+
+	case "Storage.setCookies":
+		var params struct {
+			Cookies []map[string]interface{} `json:"cookies"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %v", err)
+		}
+		cookies, err := fake.readCookies()
+		if err != nil {
+			return nil, err
+		}
+		return struct{}{}, fake.writeCookies(append(cookies, params.Cookies...))
+
+	case "Storage.clearCookies":
+		return struct{}{}, fake.writeCookies(nil)
+
+	default:
+		return nil, fmt.Errorf("'%s' wasn't found", req.Method)
+	}
+}
+
+// InputFiles returns the files last attached to a file input in any tab
+func (fake *Browser) InputFiles() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return append([]string(nil), fake.inputFiles...)
+}
+
+// setInputFiles records files attached to a file input
+func (fake *Browser) setInputFiles(files []string) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.inputFiles = files
+}
+
+// openPages returns the open tabs, oldest first
+func (fake *Browser) openPages() []*page {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return append([]*page(nil), fake.pages...)
+}
+
+// findPage returns the page with a target ID or in a tab, or nil
+func (fake *Browser) findPage(targetID string) *page {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	for _, p := range fake.pages {
+		if p.targetID == targetID || p.tabID == targetID {
+			return p
+		}
+	}
+	return nil
+}
+
+// pageURL returns the URL a page is showing
+func (fake *Browser) pageURL(p *page) string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return p.url
+}
+
+// navigate points a page at url
+func (fake *Browser) navigate(p *page, url string) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	p.url = url
+}
+
+// openPage opens a tab and reports it to every connection that asked
+func (fake *Browser) openPage(url string) *page {
+	if url == "" {
+		url = "about:blank"
+	}
+	id := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+	p := &page{targetID: id, tabID: "tab-" + id, url: url}
+
+	fake.mu.Lock()
+	fake.pages = append(fake.pages, p)
+	conns := fake.connections()
+	fake.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.pageOpened(p)
+	}
+	return p
+}
+
+// closePage closes a tab by page or tab ID and reports it to every connection
+func (fake *Browser) closePage(targetID string) bool {
+	fake.mu.Lock()
+	var closed *page
+	kept := fake.pages[:0]
+	for _, p := range fake.pages {
+		if p.targetID == targetID || p.tabID == targetID {
+			closed = p
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	fake.pages = kept
+	conns := fake.connections()
+	fake.mu.Unlock()
+
+	if closed == nil {
+		return false
+	}
+	for _, conn := range conns {
+		conn.pageClosed(closed)
+	}
+	return true
+}
+
+// connections lists the open CDP connections; fake.mu must be held
+func (fake *Browser) connections() []*cdpConn {
+	conns := make([]*cdpConn, 0, len(fake.conns))
+	for conn := range fake.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// readCookies loads the cookie store from the profile
+func (fake *Browser) readCookies() ([]map[string]interface{}, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	cookies := []map[string]interface{}{}
+	data, err := os.ReadFile(filepath.Join(fake.UserDataDir, cookieFile))
+	if os.IsNotExist(err) {
+		return cookies, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("failed to decode cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// writeCookies replaces the cookie store in the profile
+func (fake *Browser) writeCookies(cookies []map[string]interface{}) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if cookies == nil {
+		cookies = []map[string]interface{}{}
+	}
+	data, err := json.Marshal(cookies)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(fake.UserDataDir, cookieFile), data, 0644)
+}
@@ -0,0 +1,665 @@
+package browsertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Backend node IDs of the two nodes in every fake page's document
+const (
+	documentNode = iota + 1
+	fileInputNode
+)
+
+// noopMethods succeed without doing anything. They are what Puppeteer sends
+// while attaching to a page, so its pages initialize against the fake.
+var noopMethods = map[string]bool{
+	"Browser.setDownloadBehavior":         true,
+	"Emulation.setDeviceMetricsOverride":  true,
+	"Emulation.setTouchEmulationEnabled":  true,
+	"Log.enable":                          true,
+	"Network.enable":                      true,
+	"Page.enable":                         true,
+	"Page.setLifecycleEventsEnabled":      true,
+	"Performance.enable":                  true,
+	"Runtime.addBinding":                  true,
+	"Runtime.runIfWaitingForDebugger":     true,
+	"Security.setIgnoreCertificateErrors": true,
+}
+
+// page is an open tab in a fake browser. Each page's document holds a single
+// <input type="file" id="upload" multiple>, enough to attach uploads to.
+type page struct {
+	targetID string
+	tabID    string // the tab target that contains the page
+	url      string
+}
+
+// cdpRequest is a CDP command from a client
+type cdpRequest struct {
+	ID        int64           `json:"id"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+}
+
+// cdpError is the error member of a failed CDP response
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *cdpError) Error() string {
+	return e.Message
+}
+
+// cdpResponse answers one cdpRequest
+type cdpResponse struct {
+	ID        int64       `json:"id"`
+	Result    interface{} `json:"result,omitempty"`
+	Error     *cdpError   `json:"error,omitempty"`
+	SessionID string      `json:"sessionId,omitempty"`
+}
+
+// cdpEvent is a message the browser sends unprompted
+type cdpEvent struct {
+	Method    string      `json:"method"`
+	Params    interface{} `json:"params"`
+	SessionID string      `json:"sessionId,omitempty"`
+}
+
+// cdpConn is one client's WebSocket to a fake browser with the sessions it
+// has attached. Sessions are flattened onto the connection, as Puppeteer
+// expects.
+type cdpConn struct {
+	fake    *Browser
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	discover   bool // Target.setDiscoverTargets is on
+	autoAttach bool // Target.setAutoAttach is on for the browser
+	sessions   map[string]*cdpSession
+}
+
+// cdpSession is a session attached to a target. Remote objects only resolve
+// in the session that created them, as in Chromium.
+type cdpSession struct {
+	id       string
+	parent   string // session the attach was reported on; "" for the browser
+	targetID string
+	kind     string // "page", "tab" or "browser"
+	contexts int    // execution contexts created so far
+	objects  map[string]int
+	next     int
+}
+
+// serveCDP answers CDP commands on one WebSocket connection until it closes
+func (fake *Browser) serveCDP(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	conn := &cdpConn{
+		fake:     fake,
+		ws:       ws,
+		sessions: make(map[string]*cdpSession),
+	}
+
+	fake.mu.Lock()
+	if fake.exitCode != nil {
+		fake.mu.Unlock()
+		return
+	}
+	fake.conns[conn] = true
+	fake.mu.Unlock()
+
+	defer func() {
+		fake.mu.Lock()
+		delete(fake.conns, conn)
+		fake.mu.Unlock()
+	}()
+
+	for {
+		var req cdpRequest
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+
+		resp := cdpResponse{ID: req.ID, SessionID: req.SessionID}
+		result, err := conn.handle(req)
+		if cerr, ok := err.(*cdpError); ok {
+			resp.Error = cerr
+		} else if err != nil {
+			resp.Error = &cdpError{Code: -32601, Message: err.Error()}
+		} else {
+			resp.Result = result
+		}
+
+		if err := conn.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// write sends one message on the connection
+func (c *cdpConn) write(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.ws.WriteJSON(v)
+}
+
+// event sends an event on a session, or on the browser when sessionID is ""
+func (c *cdpConn) event(sessionID, method string, params interface{}) {
+	c.write(cdpEvent{Method: method, Params: params, SessionID: sessionID})
+}
+
+// handle runs one CDP command. Methods the fake does not know fail the way
+// Chromium reports an unknown method.
+func (c *cdpConn) handle(req cdpRequest) (interface{}, error) {
+	if noopMethods[req.Method] {
+		return struct{}{}, nil
+	}
+	if req.SessionID == "" {
+		return c.handleBrowser(req)
+	}
+
+	c.mu.Lock()
+	session, ok := c.sessions[req.SessionID]
+	c.mu.Unlock()
+	if !ok {
+		return nil, &cdpError{Code: -32001, Message: "Session with given id not found."}
+	}
+
+	switch session.kind {
+	case "tab":
+		return c.handleTab(session, req)
+	case "page":
+		return c.handlePage(session, req)
+	}
+	return c.handleBrowser(req)
+}
+
+// handleBrowser runs a command sent to the browser target
+func (c *cdpConn) handleBrowser(req cdpRequest) (interface{}, error) {
+	switch req.Method {
+	case "Target.getBrowserContexts":
+		return map[string]interface{}{"browserContextIds": []string{}}, nil
+
+	case "Target.setDiscoverTargets":
+		var params struct {
+			Discover bool `json:"discover"`
+		}
+		json.Unmarshal(req.Params, &params)
+
+		c.mu.Lock()
+		c.discover = params.Discover
+		c.mu.Unlock()
+
+		if params.Discover {
+			c.event("", "Target.targetCreated", map[string]interface{}{"targetInfo": c.fake.browserInfo()})
+			for _, p := range c.fake.openPages() {
+				c.event("", "Target.targetCreated", map[string]interface{}{"targetInfo": tabInfo(p, false)})
+				c.event("", "Target.targetCreated", map[string]interface{}{"targetInfo": c.fake.pageInfo(p, false)})
+			}
+		}
+		return struct{}{}, nil
+
+	case "Target.setAutoAttach":
+		c.mu.Lock()
+		c.autoAttach = true
+		c.mu.Unlock()
+
+		for _, p := range c.fake.openPages() {
+			c.attachTab(p)
+		}
+		return struct{}{}, nil
+
+	case "Target.createTarget":
+		var params struct {
+			URL string `json:"url"`
+		}
+		json.Unmarshal(req.Params, &params)
+		p := c.fake.openPage(params.URL)
+		return map[string]string{"targetId": p.targetID}, nil
+
+	case "Target.closeTarget":
+		var params struct {
+			TargetID string `json:"targetId"`
+		}
+		json.Unmarshal(req.Params, &params)
+		if !c.fake.closePage(params.TargetID) {
+			return nil, &cdpError{Code: -32602, Message: "No target with given id found"}
+		}
+		return map[string]bool{"success": true}, nil
+
+	case "Target.attachToTarget":
+		var params struct {
+			TargetID string `json:"targetId"`
+		}
+		json.Unmarshal(req.Params, &params)
+
+		var info map[string]interface{}
+		kind := "page"
+		if params.TargetID == c.fake.ID+"-browser" {
+			info, kind = c.fake.browserInfo(), "browser"
+		} else if p := c.fake.findPage(params.TargetID); p != nil {
+			info = c.fake.pageInfo(p, true)
+		} else {
+			return nil, &cdpError{Code: -32602, Message: "No target with given id found"}
+		}
+
+		session := c.attach("", params.TargetID, kind)
+		c.event("", "Target.attachedToTarget", map[string]interface{}{
+			"sessionId":          session.id,
+			"targetInfo":         info,
+			"waitingForDebugger": false,
+		})
+		return map[string]string{"sessionId": session.id}, nil
+
+	case "Target.detachFromTarget":
+		var params struct {
+			SessionID string `json:"sessionId"`
+		}
+		json.Unmarshal(req.Params, &params)
+		c.detach(params.SessionID)
+		return struct{}{}, nil
+	}
+
+	return c.fake.handle(req)
+}
+
+// handleTab runs a command sent to a tab target
+func (c *cdpConn) handleTab(session *cdpSession, req cdpRequest) (interface{}, error) {
+	switch req.Method {
+	case "Target.setAutoAttach":
+		// The tab's page attaches as its child, paused until told to run
+		if p := c.fake.findPage(session.targetID); p != nil {
+			child := c.attach(session.id, p.targetID, "page")
+			c.event(session.id, "Target.attachedToTarget", map[string]interface{}{
+				"sessionId":          child.id,
+				"targetInfo":         c.fake.pageInfo(p, true),
+				"waitingForDebugger": true,
+			})
+		}
+		return struct{}{}, nil
+	}
+	return nil, fmt.Errorf("'%s' wasn't found", req.Method)
+}
+
+// handlePage runs a command sent to a page target
+func (c *cdpConn) handlePage(session *cdpSession, req cdpRequest) (interface{}, error) {
+	p := c.fake.findPage(session.targetID)
+	if p == nil {
+		return nil, &cdpError{Code: -32000, Message: "Target closed"}
+	}
+
+	switch req.Method {
+	case "Target.setAutoAttach":
+		// Fake pages have no iframes or workers to attach to
+		return struct{}{}, nil
+
+	case "Page.getFrameTree":
+		return map[string]interface{}{"frameTree": map[string]interface{}{
+			"frame": map[string]interface{}{
+				"id":                             p.targetID,
+				"loaderId":                       p.targetID,
+				"url":                            c.fake.pageURL(p),
+				"domainAndRegistry":              "",
+				"securityOrigin":                 "://",
+				"mimeType":                       "text/html",
+				"secureContextType":              "InsecureScheme",
+				"crossOriginIsolatedContextType": "NotIsolated",
+				"gatedAPIFeatures":               []string{},
+			},
+		}}, nil
+
+	case "Page.addScriptToEvaluateOnNewDocument":
+		return map[string]string{"identifier": "1"}, nil
+
+	case "Runtime.enable":
+		c.createContext(session, p, "", true)
+		return struct{}{}, nil
+
+	case "Page.createIsolatedWorld":
+		var params struct {
+			WorldName string `json:"worldName"`
+		}
+		json.Unmarshal(req.Params, &params)
+		id := c.createContext(session, p, params.WorldName, false)
+		return map[string]int{"executionContextId": id}, nil
+
+	case "Page.navigate":
+		var params struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.URL == "" {
+			return nil, fmt.Errorf("invalid parameters: url is required")
+		}
+		c.fake.navigate(p, params.URL)
+		return map[string]string{"frameId": p.targetID, "loaderId": uuid.New().String()}, nil
+
+	case "Runtime.evaluate":
+		// Puppeteer injects its helper script this way; any object will do
+		return map[string]interface{}{"result": c.object(session, 0)}, nil
+
+	case "Runtime.callFunctionOn":
+		return c.callFunction(session, req.Params)
+
+	case "Runtime.releaseObject":
+		var params struct {
+			ObjectID string `json:"objectId"`
+		}
+		json.Unmarshal(req.Params, &params)
+		c.mu.Lock()
+		delete(session.objects, params.ObjectID)
+		c.mu.Unlock()
+		return struct{}{}, nil
+
+	case "DOM.describeNode":
+		node, err := c.resolve(session, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"node": describeNode(node)}, nil
+
+	case "DOM.resolveNode":
+		node, err := c.resolve(session, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"object": c.object(session, node)}, nil
+
+	case "DOM.setFileInputFiles":
+		var params struct {
+			Files []string `json:"files"`
+		}
+		json.Unmarshal(req.Params, &params)
+		node, err := c.resolve(session, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		if node != fileInputNode {
+			return nil, &cdpError{Code: -32000, Message: "Node is not a file input element"}
+		}
+		c.fake.setInputFiles(params.Files)
+		return struct{}{}, nil
+	}
+
+	return nil, fmt.Errorf("'%s' wasn't found", req.Method)
+}
+
+// attach records a new session on the connection
+func (c *cdpConn) attach(parent, targetID, kind string) *cdpSession {
+	session := &cdpSession{
+		id:       strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")),
+		parent:   parent,
+		targetID: targetID,
+		kind:     kind,
+		objects:  make(map[string]int),
+	}
+
+	c.mu.Lock()
+	c.sessions[session.id] = session
+	c.mu.Unlock()
+	return session
+}
+
+// attachTab auto-attaches a tab target, paused until told to run
+func (c *cdpConn) attachTab(p *page) {
+	session := c.attach("", p.tabID, "tab")
+	c.event("", "Target.attachedToTarget", map[string]interface{}{
+		"sessionId":          session.id,
+		"targetInfo":         tabInfo(p, true),
+		"waitingForDebugger": true,
+	})
+}
+
+// detach drops a session and tells the client
+func (c *cdpConn) detach(sessionID string) {
+	c.mu.Lock()
+	session, ok := c.sessions[sessionID]
+	delete(c.sessions, sessionID)
+	c.mu.Unlock()
+
+	if ok {
+		c.event(session.parent, "Target.detachedFromTarget", map[string]string{
+			"sessionId": session.id,
+			"targetId":  session.targetID,
+		})
+	}
+}
+
+// pageOpened reports a new page to a client that asked to hear about them
+func (c *cdpConn) pageOpened(p *page) {
+	c.mu.Lock()
+	discover, autoAttach := c.discover, c.autoAttach
+	c.mu.Unlock()
+
+	if discover {
+		c.event("", "Target.targetCreated", map[string]interface{}{"targetInfo": tabInfo(p, false)})
+		c.event("", "Target.targetCreated", map[string]interface{}{"targetInfo": c.fake.pageInfo(p, false)})
+	}
+	if autoAttach {
+		c.attachTab(p)
+	}
+}
+
+// pageClosed detaches the client's sessions to a closed page and its tab,
+// page sessions first as Chromium does
+func (c *cdpConn) pageClosed(p *page) {
+	c.mu.Lock()
+	var pages, tabs []string
+	for id, session := range c.sessions {
+		switch session.targetID {
+		case p.targetID:
+			pages = append(pages, id)
+		case p.tabID:
+			tabs = append(tabs, id)
+		}
+	}
+	discover := c.discover
+	c.mu.Unlock()
+
+	for _, id := range append(pages, tabs...) {
+		c.detach(id)
+	}
+	if discover {
+		c.event("", "Target.targetDestroyed", map[string]string{"targetId": p.targetID})
+		c.event("", "Target.targetDestroyed", map[string]string{"targetId": p.tabID})
+	}
+}
+
+// createContext reports a new execution context in the page's only frame
+func (c *cdpConn) createContext(session *cdpSession, p *page, name string, isDefault bool) int {
+	c.mu.Lock()
+	session.contexts++
+	id := session.contexts
+	c.mu.Unlock()
+
+	contextType := "isolated"
+	if isDefault {
+		contextType = "default"
+	}
+	c.event(session.id, "Runtime.executionContextCreated", map[string]interface{}{
+		"context": map[string]interface{}{
+			"id":       id,
+			"origin":   "://",
+			"name":     name,
+			"uniqueId": session.id + "." + strconv.Itoa(id),
+			"auxData": map[string]interface{}{
+				"isDefault": isDefault,
+				"type":      contextType,
+				"frameId":   p.targetID,
+			},
+		},
+	})
+	return id
+}
+
+// object returns a remote object for a node, or for a plain object when
+// node is 0, that only this session can refer to
+func (c *cdpConn) object(session *cdpSession, node int) map[string]interface{} {
+	c.mu.Lock()
+	session.next++
+	id := fmt.Sprintf("%s.%d", session.id, session.next)
+	session.objects[id] = node
+	c.mu.Unlock()
+
+	switch node {
+	case documentNode:
+		return map[string]interface{}{"type": "object", "subtype": "node", "className": "HTMLDocument", "description": "#document", "objectId": id}
+	case fileInputNode:
+		return map[string]interface{}{"type": "object", "subtype": "node", "className": "HTMLInputElement", "description": "input#upload", "objectId": id}
+	}
+	return map[string]interface{}{"type": "object", "className": "Object", "description": "Object", "objectId": id}
+}
+
+// lookup finds a remote object created in session
+func (c *cdpConn) lookup(session *cdpSession, objectID string) (int, error) {
+	c.mu.Lock()
+	node, ok := session.objects[objectID]
+	c.mu.Unlock()
+
+	if !ok {
+		return 0, &cdpError{Code: -32000, Message: "Could not find object with given id"}
+	}
+	return node, nil
+}
+
+// resolve finds the node a DOM command refers to. Like Chromium, a backend
+// node ID wins over an object ID.
+func (c *cdpConn) resolve(session *cdpSession, raw json.RawMessage) (int, error) {
+	var params struct {
+		BackendNodeID int    `json:"backendNodeId"`
+		ObjectID      string `json:"objectId"`
+	}
+	json.Unmarshal(raw, &params)
+
+	node := params.BackendNodeID
+	if node == 0 && params.ObjectID != "" {
+		var err error
+		if node, err = c.lookup(session, params.ObjectID); err != nil {
+			return 0, err
+		}
+	}
+	if node != documentNode && node != fileInputNode {
+		return 0, &cdpError{Code: -32000, Message: "No node with given id found"}
+	}
+	return node, nil
+}
+
+// callFunction stands in for running a function in the page. It recognizes
+// the functions Puppeteer uses to find an element and read a property.
+func (c *cdpConn) callFunction(session *cdpSession, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		FunctionDeclaration string `json:"functionDeclaration"`
+		Arguments           []struct {
+			Value    interface{} `json:"value"`
+			ObjectID string      `json:"objectId"`
+		} `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %v", err)
+	}
+
+	var args []interface{}
+	for _, arg := range params.Arguments {
+		if arg.ObjectID == "" {
+			args = append(args, arg.Value)
+			continue
+		}
+		node, err := c.lookup(session, arg.ObjectID)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, node)
+	}
+
+	fn := params.FunctionDeclaration
+	var result interface{} = map[string]string{"type": "undefined"}
+	switch {
+	case strings.Contains(fn, "return document"):
+		result = c.object(session, documentNode)
+	case strings.Contains(strings.ToLower(fn), "queryselector"):
+		result = map[string]interface{}{"type": "object", "subtype": "null", "value": nil}
+		for _, arg := range args {
+			if selector, ok := arg.(string); ok && matchesFileInput(selector) {
+				result = c.object(session, fileInputNode)
+			}
+		}
+	case strings.Contains(fn, ".multiple"):
+		result = map[string]interface{}{"type": "boolean", "value": true}
+	case len(args) > 0:
+		// Identity functions, which Puppeteer uses to clone handles
+		if node, ok := args[0].(int); ok {
+			result = c.object(session, node)
+		}
+	}
+	return map[string]interface{}{"result": result}, nil
+}
+
+// matchesFileInput reports whether a CSS selector picks the page's file input
+func matchesFileInput(selector string) bool {
+	switch strings.NewReplacer(`"`, "", "'", "", " ", "").Replace(selector) {
+	case "input", "input[type=file]", "#upload", "input#upload", "[name=upload]", "input[name=upload]":
+		return true
+	}
+	return false
+}
+
+// describeNode is the DOM.Node of one of a page's nodes
+func describeNode(node int) map[string]interface{} {
+	if node == documentNode {
+		return map[string]interface{}{"nodeId": 0, "backendNodeId": node, "nodeType": 9, "nodeName": "#document", "localName": "", "nodeValue": ""}
+	}
+	return map[string]interface{}{"nodeId": 0, "backendNodeId": node, "nodeType": 1, "nodeName": "INPUT", "localName": "input", "nodeValue": ""}
+}
+
+// browserInfo is the TargetInfo of the browser itself
+func (fake *Browser) browserInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"targetId": fake.ID + "-browser",
+		"type":     "browser",
+		"title":    "",
+		"url":      "",
+		"attached": true,
+	}
+}
+
+// tabInfo is the TargetInfo of the tab holding a page
+func tabInfo(p *page, attached bool) map[string]interface{} {
+	return map[string]interface{}{
+		"targetId":         p.tabID,
+		"type":             "tab",
+		"title":            "",
+		"url":              "",
+		"attached":         attached,
+		"canAccessOpener":  false,
+		"browserContextId": "default",
+	}
+}
+
+// pageInfo is the TargetInfo of a page
+func (fake *Browser) pageInfo(p *page, attached bool) map[string]interface{} {
+	url := fake.pageURL(p)
+	return map[string]interface{}{
+		"targetId":         p.targetID,
+		"type":             "page",
+		"title":            url,
+		"url":              url,
+		"attached":         attached,
+		"canAccessOpener":  false,
+		"browserContextId": "default",
+	}
+}
@@ -0,0 +1,308 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// localBinaries are tried in order when no Chromium binary is configured
+var localBinaries = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable"}
+
+// LocalBackend runs each browser as a headless Chromium process on this
+// machine, for development and CI hosts without Docker. Browsers do not
+// outlive the server, so sessions restored after a restart are reconciled as
+// missing, and resource limits are not enforced.
+type LocalBackend struct {
+	binary string
+	region string
+	mu     sync.Mutex
+	procs  map[string]*localBrowser // ID -> process
+}
+
+// localBrowser is one Chromium process started by the backend
+type localBrowser struct {
+	cmd         *exec.Cmd
+	sessionID   string
+	startedAt   time.Time
+	userDataDir string
+	tempProfile bool          // userDataDir was created for this browser
+	done        chan struct{} // closed when the process exits
+	exitCode    int
+}
+
+// NewLocalBackend creates a backend that launches binary, or the first
+// Chromium found on PATH when binary is empty
+func NewLocalBackend(region, binary string) (*LocalBackend, error) {
+	if binary == "" {
+		for _, candidate := range localBinaries {
+			if path, err := exec.LookPath(candidate); err == nil {
+				binary = path
+				break
+			}
+		}
+		if binary == "" {
+			return nil, fmt.Errorf("no Chromium binary found on PATH (tried %v); set CHROME_PATH", localBinaries)
+		}
+	}
+
+	return &LocalBackend{
+		binary: binary,
+		region: region,
+		procs:  make(map[string]*localBrowser),
+	}, nil
+}
+
+// Launch starts a Chromium process with its own debugging port and profile
+func (b *LocalBackend) Launch(ctx context.Context, opts LaunchBrowserOptions) (*BrowserInstance, error) {
+	userDataDir := opts.UserDataDir
+	tempProfile := userDataDir == ""
+	if tempProfile {
+		userDataDir = filepath.Join(os.TempDir(), "browser-data", opts.SessionID)
+		if err := os.MkdirAll(userDataDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create user data directory: %w", err)
+		}
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--headless=new",
+		"--remote-debugging-address=127.0.0.1",
+		"--remote-debugging-port=" + port,
+		"--user-data-dir=" + userDataDir,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-dev-shm-usage",
+	}
+	if os.Geteuid() == 0 {
+		// Chromium refuses to sandbox itself as root, as on most CI runners
+		args = append(args, "--no-sandbox")
+	}
+	args = append(args, "about:blank")
+
+	cmd := exec.Command(b.binary, args...)
+	stderr := &tailWriter{max: 4096}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", b.binary, err)
+	}
+
+	proc := &localBrowser{
+		cmd:         cmd,
+		sessionID:   opts.SessionID,
+		startedAt:   time.Now(),
+		userDataDir: userDataDir,
+		tempProfile: tempProfile,
+		done:        make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait()
+		proc.exitCode = cmd.ProcessState.ExitCode()
+		close(proc.done)
+	}()
+
+	wsURL, err := waitForDebuggerURL(ctx, port, proc.done)
+	if err != nil {
+		cmd.Process.Kill()
+		<-proc.done
+		return nil, fmt.Errorf("browser failed to become ready: %w: %s", err, strings.TrimSpace(string(stderr.buf)))
+	}
+
+	id := "local-" + uuid.New().String()
+	b.mu.Lock()
+	b.procs[id] = proc
+	b.mu.Unlock()
+
+	log.Printf("🖥️ Started local browser %s (pid %d) for session %s", id[:14], cmd.Process.Pid, opts.SessionID[:8])
+
+	return &BrowserInstance{
+		ContainerID:  id,
+		SessionID:    opts.SessionID,
+		ConnectURL:   wsURL,
+		Region:       b.region,
+		Port:         port,
+		UserDataDir:  userDataDir,
+		DownloadPath: opts.DownloadDir,
+		UploadPath:   opts.UploadDir,
+	}, nil
+}
+
+// Stop terminates a browser process, killing it if it does not exit in time
+func (b *LocalBackend) Stop(ctx context.Context, containerID string) error {
+	b.mu.Lock()
+	proc, ok := b.procs[containerID]
+	delete(b.procs, containerID)
+	b.mu.Unlock()
+
+	if !ok {
+		return ErrContainerNotFound
+	}
+
+	proc.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-proc.done:
+	case <-time.After(10 * time.Second):
+		proc.cmd.Process.Kill()
+		<-proc.done
+	case <-ctx.Done():
+		proc.cmd.Process.Kill()
+		<-proc.done
+	}
+
+	if proc.tempProfile {
+		os.RemoveAll(proc.userDataDir)
+	}
+	return nil
+}
+
+// IsHealthy reports whether the browser process is still running
+func (b *LocalBackend) IsHealthy(ctx context.Context, containerID string) bool {
+	state, err := b.InspectState(ctx, containerID)
+	if err != nil {
+		return false
+	}
+	return state.Running
+}
+
+// InspectState reports whether a browser process is running and, if not,
+// its exit code
+func (b *LocalBackend) InspectState(ctx context.Context, containerID string) (*ContainerState, error) {
+	b.mu.Lock()
+	proc, ok := b.procs[containerID]
+	b.mu.Unlock()
+
+	if !ok {
+		return nil, ErrContainerNotFound
+	}
+
+	select {
+	case <-proc.done:
+		return &ContainerState{ExitCode: proc.exitCode}, nil
+	default:
+		return &ContainerState{Running: true}, nil
+	}
+}
+
+// EnsureImage checks that the Chromium binary can be run
+func (b *LocalBackend) EnsureImage(ctx context.Context) error {
+	if _, err := exec.LookPath(b.binary); err != nil {
+		return fmt.Errorf("chromium binary %s is not available: %w", b.binary, err)
+	}
+	return nil
+}
+
+// ListManagedContainers returns the browser processes started by this backend
+func (b *LocalBackend) ListManagedContainers(ctx context.Context) ([]ManagedContainer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	managed := make([]ManagedContainer, 0, len(b.procs))
+	for id, proc := range b.procs {
+		running := true
+		select {
+		case <-proc.done:
+			running = false
+		default:
+		}
+
+		managed = append(managed, ManagedContainer{
+			ContainerID: id,
+			SessionID:   proc.sessionID,
+			Region:      b.region,
+			Running:     running,
+			CreatedAt:   proc.startedAt,
+		})
+	}
+
+	return managed, nil
+}
+
+// Close kills every browser process still running
+func (b *LocalBackend) Close() error {
+	b.mu.Lock()
+	procs := b.procs
+	b.procs = make(map[string]*localBrowser)
+	b.mu.Unlock()
+
+	for _, proc := range procs {
+		proc.cmd.Process.Kill()
+		<-proc.done
+		if proc.tempProfile {
+			os.RemoveAll(proc.userDataDir)
+		}
+	}
+	return nil
+}
+
+// tailWriter keeps the last max bytes written to it. Chromium's stderr is
+// only read once the process has exited, so it needs no lock.
+type tailWriter struct {
+	buf []byte
+	max int
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.max {
+		w.buf = w.buf[len(w.buf)-w.max:]
+	}
+	return len(p), nil
+}
+
+// freePort asks the kernel for an unused local TCP port
+func freePort() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// waitForDebuggerURL polls /json/version until Chromium reports its browser
+// WebSocket endpoint, giving up if the process exits first
+func waitForDebuggerURL(ctx context.Context, port string, exited <-chan struct{}) (string, error) {
+	url := fmt.Sprintf("http://127.0.0.1:%s/json/version", port)
+	deadline := time.After(10 * time.Second)
+
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			var version struct {
+				WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+			}
+			decodeErr := json.NewDecoder(resp.Body).Decode(&version)
+			resp.Body.Close()
+			if decodeErr == nil && version.WebSocketDebuggerURL != "" {
+				return version.WebSocketDebuggerURL, nil
+			}
+		}
+
+		select {
+		case <-exited:
+			return "", fmt.Errorf("browser process exited during startup")
+		case <-deadline:
+			return "", fmt.Errorf("browser did not become ready within 10s")
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
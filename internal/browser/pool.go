@@ -2,6 +2,7 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 	"path/filepath"
 	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
@@ -17,18 +20,44 @@ import (
 )
 
 type BrowserInstance struct {
+	ContainerID  string // the container or process running the browser
+	SessionID    string
+	ConnectURL   string
+	Region       string
+	Port         string
+	UserDataDir  string
+	DownloadPath string // where the browser sees LaunchBrowserOptions.DownloadDir
+	UploadPath   string // where the browser sees LaunchBrowserOptions.UploadDir
+}
+
+// ErrContainerNotFound is returned when Docker has no record of a container
+var ErrContainerNotFound = errors.New("container not found")
+
+// ContainerState is the part of Docker's container state used for health checks
+type ContainerState struct {
+	Running   bool
+	ExitCode  int
+	OOMKilled bool
+	Error     string
+}
+
+// ManagedContainer describes a container this service launched, as read back
+// from its Docker labels
+type ManagedContainer struct {
 	ContainerID string
 	SessionID   string
-	ConnectURL  string
 	Region      string
-	Port        string
-	UserDataDir string
+	Running     bool
+	CreatedAt   time.Time
+	Warm        bool      // idle in this process's warm pool
+	AssignedAt  time.Time // when a warm container was handed to its session
 }
 
 type Pool struct {
 	client   *client.Client
 	region   string
 	basePort int
+	warm     *warmPool // nil unless StartWarmPool was called
 }
 
 func NewPool(region string, basePort int) (*Pool, error) {
@@ -44,18 +73,59 @@ func NewPool(region string, basePort int) (*Pool, error) {
 	}, nil
 }
 
+// Per-session file directories are mounted at these paths in the container
+const (
+	DownloadPath = "/downloads"
+	UploadPath   = "/uploads"
+)
+
 type LaunchBrowserOptions struct {
 	SessionID   string
 	UserDataDir string
+	DownloadDir string // Host directory mounted at DownloadPath, if set
+	UploadDir   string // Host directory mounted read-only at UploadPath, if set
+	Resources   ResourceLimits
 }
 
-func (p *Pool) LaunchBrowser(ctx context.Context, sessionID string) (*BrowserInstance, error) {
-	return p.LaunchBrowserWithOptions(ctx, LaunchBrowserOptions{
-		SessionID: sessionID,
-	})
+// ResourceLimits constrains a browser container; zero fields are unlimited
+type ResourceLimits struct {
+	CPUShares int64   // relative CPU weight
+	CPUs      float64 // hard CPU quota in cores
+	Memory    int64   // bytes, swap included
+	ShmSize   int64   // bytes of /dev/shm; zero keeps Docker's 64MB
+	PidsLimit int64
+}
+
+// containerResources converts limits to Docker's cgroup settings
+func containerResources(limits ResourceLimits) container.Resources {
+	resources := container.Resources{
+		CPUShares: limits.CPUShares,
+		NanoCPUs:  int64(limits.CPUs * 1e9),
+		Memory:    limits.Memory,
+	}
+	if limits.Memory > 0 {
+		// Without swap a browser over its limit is OOM-killed instead of
+		// thrashing the host
+		resources.MemorySwap = limits.Memory
+	}
+	if limits.PidsLimit > 0 {
+		resources.PidsLimit = &limits.PidsLimit
+	}
+	return resources
 }
 
-func (p *Pool) LaunchBrowserWithOptions(ctx context.Context, opts LaunchBrowserOptions) (*BrowserInstance, error) {
+// Launch starts a browser container for a session, taking a warm one when
+// it can
+func (p *Pool) Launch(ctx context.Context, opts LaunchBrowserOptions) (*BrowserInstance, error) {
+	// A context's user data and /dev/shm are fixed when the container is
+	// created, so only sessions with a fresh profile and the pool's shm size
+	// can take a warm container
+	if opts.UserDataDir == "" {
+		if instance := p.claimWarm(ctx, opts); instance != nil {
+			return instance, nil
+		}
+	}
+
 	userDataDir := opts.UserDataDir
 	if userDataDir == "" {
 		userDataDir = filepath.Join(os.TempDir(), "browser-data", opts.SessionID)
@@ -64,14 +134,43 @@ func (p *Pool) LaunchBrowserWithOptions(ctx context.Context, opts LaunchBrowserO
 		}
 	}
 
+	return p.startContainer(ctx, containerSpec{
+		name:        fmt.Sprintf("session-%s", opts.SessionID[:8]),
+		sessionID:   opts.SessionID,
+		userDataDir: userDataDir,
+		downloadDir: opts.DownloadDir,
+		uploadDir:   opts.UploadDir,
+		resources:   opts.Resources,
+	})
+}
+
+// containerSpec describes a browser container to create
+type containerSpec struct {
+	name        string
+	sessionID   string // session label; warm containers use their warm ID
+	warm        bool
+	userDataDir string
+	downloadDir string
+	uploadDir   string
+	resources   ResourceLimits
+}
+
+// startContainer creates and starts a browser container and waits until
+// Chrome accepts connections
+func (p *Pool) startContainer(ctx context.Context, spec containerSpec) (*BrowserInstance, error) {
+	labels := map[string]string{
+		"session-id": spec.sessionID,
+		"region":     p.region,
+		"managed-by": "browserbase-mini",
+	}
+	if spec.warm {
+		labels["warm-pool"] = "true"
+	}
+
 	// Use browserless/chrome - it just works!
 	containerConfig := &container.Config{
-		Image: "browserless/chrome:latest",
-		Labels: map[string]string{
-			"session-id": opts.SessionID,
-			"region":     p.region,
-			"managed-by": "browserbase-mini",
-		},
+		Image:  "browserless/chrome:latest",
+		Labels: labels,
 		Env: []string{
 			"CONNECTION_TIMEOUT=-1",        // Disable connection timeout
 			"MAX_CONCURRENT_SESSIONS=1",    // Only allow 1 session per container
@@ -94,14 +193,31 @@ func (p *Pool) LaunchBrowserWithOptions(ctx context.Context, opts LaunchBrowserO
 			},
 		},
 		AutoRemove: false,
+		Resources:  containerResources(spec.resources),
+		ShmSize:    spec.resources.ShmSize,
 		Mounts: []mount.Mount{
 			{
 				Type:   mount.TypeBind,
-				Source: userDataDir,
+				Source: spec.userDataDir,
 				Target: "/data",
 			},
 		},
 	}
+	if spec.downloadDir != "" {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: spec.downloadDir,
+			Target: DownloadPath,
+		})
+	}
+	if spec.uploadDir != "" {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   spec.uploadDir,
+			Target:   UploadPath,
+			ReadOnly: true,
+		})
+	}
 
 	resp, err := p.client.ContainerCreate(
 		ctx,
@@ -109,42 +225,52 @@ func (p *Pool) LaunchBrowserWithOptions(ctx context.Context, opts LaunchBrowserO
 		hostConfig,
 		nil,
 		nil,
-		fmt.Sprintf("session-%s", opts.SessionID[:8]),
+		spec.name,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container: %w", err)
 	}
 
 	if err := p.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		p.discardContainer(resp.ID)
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
 
 	// Wait for container to be ready
 	inspect, err := p.client.ContainerInspect(ctx, resp.ID)
 	if err != nil {
+		p.discardContainer(resp.ID)
 		return nil, fmt.Errorf("failed to inspect container: %w", err)
 	}
 
 	port := inspect.NetworkSettings.Ports["3000/tcp"][0].HostPort
 
 	// Wait for the browser to be ready by checking the /json/version endpoint
-	if err := p.waitForBrowserReady(port); err != nil {
+	if err := waitForBrowserReady(port); err != nil {
+		p.discardContainer(resp.ID)
 		return nil, fmt.Errorf("browser failed to become ready: %w", err)
 	}
 
 	instance := &BrowserInstance{
 		ContainerID: resp.ID,
-		SessionID:   opts.SessionID,
+		SessionID:   spec.sessionID,
 		ConnectURL:  fmt.Sprintf("ws://localhost:%s", port),
 		Region:      p.region,
 		Port:        port,
-		UserDataDir: userDataDir,
+		UserDataDir: spec.userDataDir,
+	}
+	if spec.downloadDir != "" {
+		instance.DownloadPath = DownloadPath
+	}
+	if spec.uploadDir != "" {
+		instance.UploadPath = UploadPath
 	}
 
 	return instance, nil
 }
 
-func (p *Pool) StopBrowser(ctx context.Context, containerID string) error {
+// Stop stops and removes a browser container
+func (p *Pool) Stop(ctx context.Context, containerID string) error {
 	timeout := 10
 	stopOptions := container.StopOptions{
 		Timeout: &timeout,
@@ -161,12 +287,71 @@ func (p *Pool) StopBrowser(ctx context.Context, containerID string) error {
 	return nil
 }
 
+// ListManagedContainers returns every container labeled as ours in this region,
+// including stopped ones
+func (p *Pool) ListManagedContainers(ctx context.Context) ([]ManagedContainer, error) {
+	containers, err := p.client.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", "managed-by=browserbase-mini"),
+			filters.Arg("label", "region="+p.region),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	managed := make([]ManagedContainer, 0, len(containers))
+	for _, c := range containers {
+		mc := ManagedContainer{
+			ContainerID: c.ID,
+			SessionID:   c.Labels["session-id"],
+			Region:      c.Labels["region"],
+			Running:     c.State == container.StateRunning,
+			CreatedAt:   time.Unix(c.Created, 0),
+		}
+		if p.warm != nil {
+			mc.Warm, mc.AssignedAt = p.warm.state(c.ID)
+		}
+		managed = append(managed, mc)
+	}
+
+	return managed, nil
+}
+
+// discardContainer force-removes a container from a failed launch. It uses its
+// own context because the launch context may be what just expired.
+func (p *Pool) discardContainer(containerID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true})
+}
+
 func (p *Pool) IsHealthy(ctx context.Context, containerID string) bool {
-	inspect, err := p.client.ContainerInspect(ctx, containerID)
+	state, err := p.InspectState(ctx, containerID)
 	if err != nil {
 		return false
 	}
-	return inspect.State.Running
+	return state.Running
+}
+
+// InspectState reports whether a container is running and, if not, how it exited
+func (p *Pool) InspectState(ctx context.Context, containerID string) (*ContainerState, error) {
+	inspect, err := p.client.ContainerInspect(ctx, containerID)
+	if cerrdefs.IsNotFound(err) {
+		return nil, ErrContainerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	return &ContainerState{
+		Running:   inspect.State.Running,
+		ExitCode:  inspect.State.ExitCode,
+		OOMKilled: inspect.State.OOMKilled,
+		Error:     inspect.State.Error,
+	}, nil
 }
 
 func (p *Pool) EnsureImage(ctx context.Context) error {
@@ -198,7 +383,7 @@ func (p *Pool) Close() error {
 }
 
 // waitForBrowserReady waits for the browser to be ready by checking the /json/version endpoint
-func (p *Pool) waitForBrowserReady(port string) error {
+func waitForBrowserReady(port string) error {
 	url := fmt.Sprintf("http://localhost:%s/json/version", port)
 	maxRetries := 20 // 10 seconds total (20 * 500ms)
 
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Stats is one reading of a browser's resource usage. Network and block IO
+// are totals since the browser started.
+type Stats struct {
+	Time        time.Time
+	CPUPercent  float64 // 100 is one core fully busy
+	MemoryUsage int64   // bytes, excluding reclaimable page cache
+	MemoryLimit int64   // bytes; the host's memory when unconstrained
+	NetworkRx   int64
+	NetworkTx   int64
+	BlockRead   int64
+	BlockWrite  int64
+}
+
+// Stats takes one reading from Docker. Docker samples the container twice
+// to work out CPU usage, so this takes about a second.
+func (p *Pool) Stats(ctx context.Context, containerID string) (*Stats, error) {
+	resp, err := p.client.ContainerStats(ctx, containerID, false)
+	if cerrdefs.IsNotFound(err) {
+		return nil, ErrContainerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+	return statsFromDocker(raw), nil
+}
+
+// StreamStats follows Docker's stats stream, which yields a reading a second
+func (p *Pool) StreamStats(ctx context.Context, containerID string, fn func(*Stats) error) error {
+	resp, err := p.client.ContainerStats(ctx, containerID, true)
+	if cerrdefs.IsNotFound(err) {
+		return ErrContainerNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stream container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var raw container.StatsResponse
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				// The container stopped or the caller went away
+				return nil
+			}
+			return fmt.Errorf("failed to decode container stats: %w", err)
+		}
+		if raw.Read.IsZero() {
+			// Docker sends an empty reading once a container has stopped
+			return nil
+		}
+		if err := fn(statsFromDocker(raw)); err != nil {
+			return err
+		}
+	}
+}
+
+// statsFromDocker condenses a Docker stats reading the way `docker stats`
+// presents it
+func statsFromDocker(raw container.StatsResponse) *Stats {
+	stats := &Stats{
+		Time:        raw.Read,
+		MemoryUsage: int64(raw.MemoryStats.Usage),
+		MemoryLimit: int64(raw.MemoryStats.Limit),
+	}
+
+	// Page cache can be reclaimed before the OOM killer runs, so it is not
+	// counted as usage (cgroup v2 key first, then v1)
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if cache, ok := raw.MemoryStats.Stats[key]; ok && cache < raw.MemoryStats.Usage {
+			stats.MemoryUsage -= int64(cache)
+			break
+		}
+	}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	cpus := float64(raw.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * cpus * 100
+	}
+
+	for _, network := range raw.Networks {
+		stats.NetworkRx += int64(network.RxBytes)
+		stats.NetworkTx += int64(network.TxBytes)
+	}
+
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += int64(entry.Value)
+		case "write":
+			stats.BlockWrite += int64(entry.Value)
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,453 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/uuid"
+)
+
+const (
+	// warmCheckInterval is how often the pool retires expired containers and
+	// tops itself up when no launch has asked it to
+	warmCheckInterval = 30 * time.Second
+
+	// assignedRetention is how long handed-out containers are remembered, so
+	// the reconciler does not reap one before its session records it
+	assignedRetention = 10 * time.Minute
+)
+
+// WarmPoolConfig sizes the pool of pre-started containers kept in a region
+type WarmPoolConfig struct {
+	Size int           // ready containers to keep; 0 disables the pool
+	TTL  time.Duration // idle containers older than this are replaced
+	Dir  string        // host directory for staging download and upload mounts
+	// ShmSize is the /dev/shm of warm containers in bytes. It cannot change
+	// once a container exists, so sessions asking for another size start
+	// their own container.
+	ShmSize int64
+}
+
+// WarmPoolStats reports a warm pool's occupancy and hit rate
+type WarmPoolStats struct {
+	Size     int
+	Idle     int
+	Starting int
+	Hits     int64
+	Misses   int64
+}
+
+// warmContainer is a ready browser not yet assigned to a session
+type warmContainer struct {
+	instance   *BrowserInstance
+	stagingDir string // holds the download and upload mount sources
+	startedAt  time.Time
+}
+
+// warmPool holds the idle containers of one region
+type warmPool struct {
+	cfg      WarmPoolConfig
+	mu       sync.Mutex
+	idle     []*warmContainer // oldest first
+	starting int
+	assigned map[string]time.Time // containerID -> when it was handed out
+	hits     int64
+	misses   int64
+	refill   chan struct{}
+}
+
+// StartWarmPool keeps cfg.Size ready containers in this region until ctx is
+// cancelled. Launches without a context take one instead of starting their
+// own. It must be called before the pool serves launches.
+func (p *Pool) StartWarmPool(ctx context.Context, cfg WarmPoolConfig) error {
+	if cfg.Size <= 0 {
+		return nil
+	}
+
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve warm pool directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create warm pool directory: %w", err)
+	}
+	cfg.Dir = dir
+
+	p.warm = &warmPool{
+		cfg:      cfg,
+		assigned: make(map[string]time.Time),
+		refill:   make(chan struct{}, 1),
+	}
+
+	go p.maintainWarmPool(ctx)
+	return nil
+}
+
+// maintainWarmPool retires expired containers and refills the pool
+func (p *Pool) maintainWarmPool(ctx context.Context) {
+	ticker := time.NewTicker(warmCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.retireExpired()
+		p.fillWarmPool(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.warm.refill:
+		}
+	}
+}
+
+// fillWarmPool starts containers one at a time until the pool is full
+func (p *Pool) fillWarmPool(ctx context.Context) {
+	for ctx.Err() == nil {
+		p.warm.mu.Lock()
+		if len(p.warm.idle)+p.warm.starting >= p.warm.cfg.Size {
+			p.warm.mu.Unlock()
+			return
+		}
+		p.warm.starting++
+		p.warm.mu.Unlock()
+
+		w, err := p.startWarm(ctx)
+
+		p.warm.mu.Lock()
+		p.warm.starting--
+		if err == nil {
+			p.warm.idle = append(p.warm.idle, w)
+		}
+		p.warm.mu.Unlock()
+
+		if err != nil {
+			log.Printf("⚠️ Failed to start warm container in %s: %v", p.region, err)
+			return
+		}
+	}
+}
+
+// startWarm starts one unassigned container with staging mounts
+func (p *Pool) startWarm(ctx context.Context) (*warmContainer, error) {
+	id := uuid.New().String()
+
+	stagingDir := filepath.Join(p.warm.cfg.Dir, id)
+	downloadDir := filepath.Join(stagingDir, "downloads")
+	uploadDir := filepath.Join(stagingDir, "uploads")
+	for _, dir := range []string{downloadDir, uploadDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create staging directory: %w", err)
+		}
+	}
+	// Chrome runs as an unprivileged user inside the container
+	if err := os.Chmod(downloadDir, 0777); err != nil {
+		os.RemoveAll(stagingDir)
+		return nil, fmt.Errorf("failed to open up download directory: %w", err)
+	}
+
+	userDataDir := filepath.Join(os.TempDir(), "browser-data", "warm-"+id)
+	if err := os.MkdirAll(userDataDir, 0755); err != nil {
+		os.RemoveAll(stagingDir)
+		return nil, fmt.Errorf("failed to create user data directory: %w", err)
+	}
+
+	launchCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	instance, err := p.startContainer(launchCtx, containerSpec{
+		name:        fmt.Sprintf("warm-%s", id[:8]),
+		sessionID:   id,
+		warm:        true,
+		userDataDir: userDataDir,
+		downloadDir: downloadDir,
+		uploadDir:   uploadDir,
+		resources:   ResourceLimits{ShmSize: p.warm.cfg.ShmSize},
+	})
+	if err != nil {
+		os.RemoveAll(stagingDir)
+		os.RemoveAll(userDataDir)
+		return nil, err
+	}
+
+	return &warmContainer{
+		instance:   instance,
+		stagingDir: stagingDir,
+		startedAt:  time.Now(),
+	}, nil
+}
+
+// claimWarm hands a ready container to a session, or returns nil when the
+// pool is empty or disabled
+func (p *Pool) claimWarm(ctx context.Context, opts LaunchBrowserOptions) *BrowserInstance {
+	if p.warm == nil {
+		return nil
+	}
+	if shm := opts.Resources.ShmSize; shm != 0 && shm != p.warm.cfg.ShmSize {
+		return nil
+	}
+
+	for {
+		p.warm.mu.Lock()
+		if len(p.warm.idle) == 0 {
+			p.warm.misses++
+			p.warm.mu.Unlock()
+			p.requestRefill()
+			return nil
+		}
+		w := p.warm.idle[0]
+		p.warm.idle = p.warm.idle[1:]
+		p.warm.mu.Unlock()
+		p.requestRefill()
+
+		instance, err := p.assignWarm(ctx, w, opts)
+		var dirErr *sessionDirError
+		if errors.As(err, &dirErr) {
+			// The container is fine; the session launches its own instead
+			log.Printf("⚠️ Could not hand warm container %s to session %s: %v", w.instance.ContainerID[:12], opts.SessionID[:8], err)
+			p.warm.mu.Lock()
+			p.warm.idle = append([]*warmContainer{w}, p.warm.idle...)
+			p.warm.misses++
+			p.warm.mu.Unlock()
+			return nil
+		}
+		if err != nil {
+			log.Printf("⚠️ Discarding warm container %s: %v", w.instance.ContainerID[:12], err)
+			p.retireWarm(w)
+			continue
+		}
+
+		p.warm.mu.Lock()
+		p.warm.hits++
+		p.warm.assigned[instance.ContainerID] = time.Now()
+		p.warm.mu.Unlock()
+
+		log.Printf("🔥 Assigned warm container %s to session %s", instance.ContainerID[:12], opts.SessionID[:8])
+		return instance
+	}
+}
+
+// sessionDirError is an assignWarm failure caused by the session's
+// directories rather than the container, which stays usable
+type sessionDirError struct {
+	err error
+}
+
+func (e *sessionDirError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sessionDirError) Unwrap() error {
+	return e.err
+}
+
+// assignWarm moves a warm container's staging mounts to the session's
+// directories and applies the session's resource limits. Bind mounts follow a
+// directory across a rename on the same filesystem, so the container sees the
+// session's files from then on.
+func (p *Pool) assignWarm(ctx context.Context, w *warmContainer, opts LaunchBrowserOptions) (*BrowserInstance, error) {
+	state, err := p.InspectState(ctx, w.instance.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Running {
+		return nil, fmt.Errorf("container exited with code %d", state.ExitCode)
+	}
+
+	type move struct{ source, target string }
+	var moves []move
+	for _, mv := range []move{
+		{filepath.Join(w.stagingDir, "downloads"), opts.DownloadDir},
+		{filepath.Join(w.stagingDir, "uploads"), opts.UploadDir},
+	} {
+		if mv.target != "" {
+			moves = append(moves, mv)
+		}
+	}
+	for i, mv := range moves {
+		if err := adoptDir(mv.source, mv.target); err != nil {
+			// Put back what already moved so the container can serve another session
+			for _, done := range moves[:i] {
+				releaseDir(done.source, done.target)
+			}
+			return nil, &sessionDirError{err}
+		}
+	}
+	os.RemoveAll(w.stagingDir)
+
+	limits := opts.Resources
+	limits.ShmSize = 0
+	if limits != (ResourceLimits{}) {
+		_, err := p.client.ContainerUpdate(ctx, w.instance.ContainerID, container.UpdateConfig{
+			Resources: containerResources(limits),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply resource limits: %w", err)
+		}
+	}
+
+	instance := *w.instance
+	instance.SessionID = opts.SessionID
+	return &instance, nil
+}
+
+// adoptDir makes the mounted source directory take target's place. Files
+// already in target, such as uploads made while the session was queued, move
+// into source first so they stay visible to the browser.
+func adoptDir(source, target string) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	restore := func() {
+		// Files may have landed in a recreated target meanwhile
+		os.MkdirAll(target, info.Mode().Perm())
+		os.Chmod(target, info.Mode().Perm())
+		moveEntries(source, target)
+	}
+
+	if err := moveEntries(target, source); err != nil {
+		restore()
+		return fmt.Errorf("failed to move %s into staging: %w", target, err)
+	}
+	if err := os.Remove(target); err != nil {
+		restore()
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	if err := os.Rename(source, target); err != nil {
+		restore()
+		return fmt.Errorf("failed to move staging directory: %w", err)
+	}
+	return nil
+}
+
+// releaseDir undoes adoptDir, returning the mounted directory to source and
+// leaving the session's files in a fresh target
+func releaseDir(source, target string) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return
+	}
+	if err := os.Rename(target, source); err != nil {
+		return
+	}
+	if err := os.Mkdir(target, info.Mode().Perm()); err == nil {
+		os.Chmod(target, info.Mode().Perm())
+		moveEntries(source, target)
+	}
+}
+
+// moveEntries renames everything in from into to
+func moveEntries(from, to string) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retireExpired stops idle containers that have outlived the TTL
+func (p *Pool) retireExpired() {
+	p.warm.mu.Lock()
+	var expired []*warmContainer
+	kept := p.warm.idle[:0]
+	for _, w := range p.warm.idle {
+		if p.warm.cfg.TTL > 0 && time.Since(w.startedAt) > p.warm.cfg.TTL {
+			expired = append(expired, w)
+		} else {
+			kept = append(kept, w)
+		}
+	}
+	p.warm.idle = kept
+
+	for containerID, assignedAt := range p.warm.assigned {
+		if time.Since(assignedAt) > assignedRetention {
+			delete(p.warm.assigned, containerID)
+		}
+	}
+	p.warm.mu.Unlock()
+
+	for _, w := range expired {
+		log.Printf("♻️ Retiring idle warm container %s in %s", w.instance.ContainerID[:12], p.region)
+		p.retireWarm(w)
+	}
+}
+
+// retireWarm removes a warm container and its directories
+func (p *Pool) retireWarm(w *warmContainer) {
+	p.discardContainer(w.instance.ContainerID)
+	os.RemoveAll(w.stagingDir)
+	os.RemoveAll(w.instance.UserDataDir)
+}
+
+// DrainWarmPool removes every idle warm container, for shutdown
+func (p *Pool) DrainWarmPool() {
+	if p.warm == nil {
+		return
+	}
+
+	p.warm.mu.Lock()
+	idle := p.warm.idle
+	p.warm.idle = nil
+	p.warm.mu.Unlock()
+
+	for _, w := range idle {
+		p.retireWarm(w)
+	}
+}
+
+// WarmPoolStats reports the warm pool's occupancy; ok is false when the pool
+// is disabled
+func (p *Pool) WarmPoolStats() (stats WarmPoolStats, ok bool) {
+	if p.warm == nil {
+		return WarmPoolStats{}, false
+	}
+
+	p.warm.mu.Lock()
+	defer p.warm.mu.Unlock()
+
+	return WarmPoolStats{
+		Size:     p.warm.cfg.Size,
+		Idle:     len(p.warm.idle),
+		Starting: p.warm.starting,
+		Hits:     p.warm.hits,
+		Misses:   p.warm.misses,
+	}, true
+}
+
+// requestRefill wakes the maintenance loop without blocking
+func (p *Pool) requestRefill() {
+	select {
+	case p.warm.refill <- struct{}{}:
+	default:
+	}
+}
+
+// state reports whether a container is idle in the pool and when it was
+// handed to a session, if it was
+func (w *warmPool) state(containerID string) (idle bool, assignedAt time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, c := range w.idle {
+		if c.instance.ContainerID == containerID {
+			return true, time.Time{}
+		}
+	}
+	return false, w.assigned[containerID]
+}
@@ -0,0 +1,49 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdoptDir(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "staging", "uploads")
+	target := filepath.Join(root, "session", "uploads")
+	for _, dir := range []string{source, target} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sourceInfo, err := os.Stat(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An upload made while the session was still queued
+	if err := os.WriteFile(filepath.Join(target, "early.csv"), []byte("a,b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := adoptDir(source, target); err != nil {
+		t.Fatalf("adoptDir: %v", err)
+	}
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(sourceInfo, targetInfo) {
+		t.Error("target is not the mounted staging directory")
+	}
+	if data, err := os.ReadFile(filepath.Join(target, "early.csv")); err != nil || string(data) != "a,b" {
+		t.Errorf("early upload = %q, %v", data, err)
+	}
+
+	releaseDir(source, target)
+	if info, err := os.Stat(source); err != nil || !os.SameFile(sourceInfo, info) {
+		t.Errorf("staging directory was not returned: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "early.csv")); err != nil {
+		t.Errorf("early upload did not stay with the session: %v", err)
+	}
+}
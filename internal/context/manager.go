@@ -116,6 +116,22 @@ func (m *Manager) SaveContextData(contextID, userDataDir string) error {
 	return nil
 }
 
+// StorageUsed returns the total size in bytes of a project's saved context data
+func (m *Manager) StorageUsed(projectID string) int64 {
+	var total int64
+	m.contexts.Range(func(key, value interface{}) bool {
+		ctx := value.(*models.Context)
+		if ctx.ProjectID != projectID || ctx.DataPath == "" {
+			return true
+		}
+		if info, err := os.Stat(ctx.DataPath); err == nil {
+			total += info.Size()
+		}
+		return true
+	})
+	return total
+}
+
 // LoadContextData extracts context data to a temporary directory
 func (m *Manager) LoadContextData(contextID string) (string, error) {
 	ctx, err := m.GetContext(contextID)
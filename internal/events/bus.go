@@ -0,0 +1,157 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies what happened
+type Type string
+
+const (
+	SessionCreated   Type = "session.created"
+	SessionRunning   Type = "session.running"
+	SessionCompleted Type = "session.completed"
+	SessionTimedOut  Type = "session.timed_out"
+	SessionError     Type = "session.error"
+	QuotaWarning     Type = "session.quota_warning"
+	ContextSaved     Type = "context.saved"
+)
+
+// Event is a single lifecycle notification. IDs increase monotonically, also
+// across restarts, so clients can resume with Last-Event-ID.
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      Type        `json:"type"`
+	ProjectID string      `json:"projectId"`
+	SessionID string      `json:"sessionId,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// Filter selects events by project and/or session; empty fields match all
+type Filter struct {
+	ProjectID string
+	SessionID string
+}
+
+// Matches reports whether the event passes the filter
+func (f Filter) Matches(e Event) bool {
+	if f.ProjectID != "" && e.ProjectID != f.ProjectID {
+		return false
+	}
+	if f.SessionID != "" && e.SessionID != f.SessionID {
+		return false
+	}
+	return true
+}
+
+// Subscription delivers matching events on C. C is closed when the
+// subscriber falls too far behind or unsubscribes; resume from the last
+// received ID to catch up from the replay buffer.
+type Subscription struct {
+	C <-chan Event
+	// Truncated is set when some events after the requested lastEventID had
+	// already left the replay buffer, or were published before a restart
+	Truncated bool
+	ch        chan Event
+	filter    Filter
+}
+
+// Bus fans events out to subscribers and keeps a bounded replay buffer
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	replay      []Event // ring buffer, oldest at replayStart
+	replayStart int
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus creates a bus that remembers the last replaySize events. IDs start
+// at the current time in microseconds, so they keep increasing across
+// restarts while staying exact as JavaScript numbers.
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		nextID:      uint64(time.Now().UnixMicro()),
+		replay:      make([]Event, 0, replaySize),
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns an ID and timestamp to e and delivers it
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.ID = b.nextID
+	b.nextID++
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	if len(b.replay) < b.replaySize {
+		b.replay = append(b.replay, e)
+	} else {
+		b.replay[b.replayStart] = e
+		b.replayStart = (b.replayStart + 1) % b.replaySize
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(e) {
+			continue
+		}
+
+		select {
+		case sub.ch <- e:
+		default:
+			// Never block publishers on a slow reader
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+
+	return e
+}
+
+// Subscribe registers a subscriber and returns the buffered events after
+// lastEventID that match the filter. Pass 0 to skip replay.
+func (b *Bus) Subscribe(filter Filter, lastEventID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	truncated := false
+	if lastEventID > 0 {
+		oldestID := b.nextID
+		if len(b.replay) > 0 {
+			oldestID = b.replay[b.replayStart].ID
+		}
+		truncated = lastEventID+1 < oldestID || lastEventID >= b.nextID
+
+		for i := 0; i < len(b.replay); i++ {
+			e := b.replay[(b.replayStart+i)%len(b.replay)]
+			if e.ID > lastEventID && filter.Matches(e) {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	ch := make(chan Event, 64)
+	sub := &Subscription{C: ch, Truncated: truncated, ch: ch, filter: filter}
+	b.subscribers[sub] = struct{}{}
+
+	return sub, missed
+}
+
+// Unsubscribe stops delivery to sub and closes its channel
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
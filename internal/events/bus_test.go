@@ -0,0 +1,43 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIDsIncreaseAcrossRestarts(t *testing.T) {
+	first := NewBus(10).Publish(Event{Type: SessionCreated})
+	time.Sleep(time.Millisecond) // a restart takes far longer
+	second := NewBus(10).Publish(Event{Type: SessionCreated})
+	if second.ID <= first.ID {
+		t.Fatalf("restarted bus issued %d after %d", second.ID, first.ID)
+	}
+}
+
+func TestSubscribeReportsTruncatedReplay(t *testing.T) {
+	bus := NewBus(3)
+	var ids []uint64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, bus.Publish(Event{Type: SessionRunning, ProjectID: "p"}).ID)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		lastEventID uint64
+		missed      int
+		truncated   bool
+	}{
+		{"no resume", 0, 0, false},
+		{"buffered", ids[1], 3, false},
+		{"latest", ids[4], 0, false},
+		{"evicted", ids[0], 3, true},
+		{"previous run", ids[0] - 1000, 3, true},
+		{"unknown", ids[4] + 1, 0, true},
+	} {
+		sub, missed := bus.Subscribe(Filter{}, tc.lastEventID)
+		bus.Unsubscribe(sub)
+		if len(missed) != tc.missed || sub.Truncated != tc.truncated {
+			t.Errorf("%s: got %d missed, truncated %v; want %d, %v", tc.name, len(missed), sub.Truncated, tc.missed, tc.truncated)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// Defaults for projects registered without explicit limits
+const (
+	DefaultConcurrency    = 10
+	DefaultSessionTimeout = 3600
+	DefaultWeight         = 1
+)
+
+// DefaultResources are the browser limits of projects registered without
+// their own: enough for heavy pages, but not enough for one runaway tab to
+// starve the rest of the host
+var DefaultResources = models.ResourceLimits{
+	CPUs:      2,
+	MemoryMB:  2048,
+	ShmSizeMB: 512,
+	PidsLimit: 1024,
+}
+
+// Bounds on project settings
+const (
+	maxConcurrency = 1000
+	maxWeight      = 100
+	minTimeout     = 60
+	maxTimeout     = 21600
+)
+
+// Bounds on browser resource limits. Chromium needs a few hundred MB and
+// many threads just to open a page.
+const (
+	minCPUShares = 2
+	maxCPUShares = 262144
+	maxCPUs      = 64
+	minMemoryMB  = 256
+	maxMemoryMB  = 65536
+	minShmSizeMB = 64
+	minPidsLimit = 64
+	maxPidsLimit = 32768
+)
+
+// ErrNotFound is returned for project IDs that are not registered
+var ErrNotFound = errors.New("project not found")
+
+// validID keeps project IDs safe to use as file names and URL segments
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// Manager is the registry of projects sessions can be created in
+type Manager struct {
+	projects sync.Map // projectID -> *models.Project
+	store    store.ProjectStore
+	mu       sync.Mutex // serializes registry changes
+}
+
+// NewManager creates a project registry and loads stored projects
+func NewManager(projectStore store.ProjectStore) (*Manager, error) {
+	m := &Manager{store: projectStore}
+
+	projects, err := projectStore.ListProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	for _, project := range projects {
+		if project.Weight == 0 {
+			// Records from before fair-share weights
+			project.Weight = DefaultWeight
+		}
+		m.projects.Store(project.ID, project)
+	}
+
+	return m, nil
+}
+
+// CreateProject registers a new project
+func (m *Manager) CreateProject(req models.CreateProjectRequest) (*models.Project, error) {
+	if req.ID == "" {
+		req.ID = uuid.New().String()
+	}
+	if !validID.MatchString(req.ID) {
+		return nil, fmt.Errorf("id must be 1 to 64 letters, digits, '-' or '_'")
+	}
+	if req.Name == "" {
+		req.Name = req.ID
+	}
+	if req.Concurrency == 0 {
+		req.Concurrency = DefaultConcurrency
+	}
+	if req.DefaultTimeout == 0 {
+		req.DefaultTimeout = DefaultSessionTimeout
+	}
+	if req.Weight == 0 {
+		req.Weight = DefaultWeight
+	}
+	if req.Resources == nil {
+		req.Resources = &DefaultResources
+	}
+	if err := validateLimits(req.Concurrency, req.DefaultTimeout, req.Weight); err != nil {
+		return nil, err
+	}
+	if err := ValidateResources(*req.Resources); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.projects.Load(req.ID); exists {
+		return nil, fmt.Errorf("project %s already exists", req.ID)
+	}
+
+	now := time.Now()
+	project := &models.Project{
+		ID:             req.ID,
+		Name:           req.Name,
+		Concurrency:    req.Concurrency,
+		DefaultTimeout: req.DefaultTimeout,
+		Weight:         req.Weight,
+		Resources:      *req.Resources,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := m.store.SaveProject(project); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	m.projects.Store(project.ID, project)
+	return project, nil
+}
+
+// GetProject retrieves a project by ID
+func (m *Manager) GetProject(id string) (*models.Project, error) {
+	value, ok := m.projects.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return value.(*models.Project), nil
+}
+
+// ListProjects returns every project, oldest first
+func (m *Manager) ListProjects() []*models.Project {
+	projects := []*models.Project{}
+	m.projects.Range(func(key, value interface{}) bool {
+		projects = append(projects, value.(*models.Project))
+		return true
+	})
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.Before(projects[j].CreatedAt)
+	})
+	return projects
+}
+
+// UpdateProject changes a project's name or limits. New limits apply to the
+// next session created; lowering concurrency never ends running sessions.
+func (m *Manager) UpdateProject(id string, req models.UpdateProjectRequest) (*models.Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, err := m.GetProject(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	if req.Name != nil {
+		updated.Name = *req.Name
+	}
+	if req.Concurrency != nil {
+		updated.Concurrency = *req.Concurrency
+	}
+	if req.DefaultTimeout != nil {
+		updated.DefaultTimeout = *req.DefaultTimeout
+	}
+	if req.Weight != nil {
+		updated.Weight = *req.Weight
+	}
+	if req.Resources != nil {
+		updated.Resources = *req.Resources
+	}
+	if err := validateLimits(updated.Concurrency, updated.DefaultTimeout, updated.Weight); err != nil {
+		return nil, err
+	}
+	if err := ValidateResources(updated.Resources); err != nil {
+		return nil, err
+	}
+	updated.UpdatedAt = time.Now()
+
+	if err := m.store.SaveProject(&updated); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	m.projects.Store(id, &updated)
+	return &updated, nil
+}
+
+// DeleteProject removes a project from the registry. Its running sessions
+// keep running, but no new ones can be created in it.
+func (m *Manager) DeleteProject(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.GetProject(id); err != nil {
+		return err
+	}
+
+	if err := m.store.DeleteProject(id); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	m.projects.Delete(id)
+
+	return nil
+}
+
+// validateLimits checks a project's concurrency, default timeout and weight
+func validateLimits(concurrency, defaultTimeout, weight int) error {
+	if concurrency < 1 || concurrency > maxConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", maxConcurrency)
+	}
+	if defaultTimeout < minTimeout || defaultTimeout > maxTimeout {
+		return fmt.Errorf("defaultTimeout must be between %d and %d seconds", minTimeout, maxTimeout)
+	}
+	if weight < 1 || weight > maxWeight {
+		return fmt.Errorf("weight must be between 1 and %d", maxWeight)
+	}
+	return nil
+}
+
+// ValidateResources checks browser resource limits against what Docker and
+// Chromium can run with. Zero fields are unconstrained and not checked.
+func ValidateResources(limits models.ResourceLimits) error {
+	if limits.CPUShares != 0 && (limits.CPUShares < minCPUShares || limits.CPUShares > maxCPUShares) {
+		return fmt.Errorf("cpuShares must be between %d and %d", minCPUShares, maxCPUShares)
+	}
+	if limits.CPUs < 0 || limits.CPUs > maxCPUs {
+		return fmt.Errorf("cpus must be between 0 and %d", maxCPUs)
+	}
+	if limits.MemoryMB != 0 && (limits.MemoryMB < minMemoryMB || limits.MemoryMB > maxMemoryMB) {
+		return fmt.Errorf("memoryMb must be between %d and %d", minMemoryMB, maxMemoryMB)
+	}
+	if limits.ShmSizeMB != 0 && limits.ShmSizeMB < minShmSizeMB {
+		return fmt.Errorf("shmSizeMb must be at least %d", minShmSizeMB)
+	}
+	if limits.MemoryMB != 0 && limits.ShmSizeMB > limits.MemoryMB {
+		// /dev/shm is charged to the container's memory
+		return fmt.Errorf("shmSizeMb cannot exceed memoryMb")
+	}
+	if limits.PidsLimit != 0 && (limits.PidsLimit < minPidsLimit || limits.PidsLimit > maxPidsLimit) {
+		return fmt.Errorf("pidsLimit must be between %d and %d", minPidsLimit, maxPidsLimit)
+	}
+	return nil
+}
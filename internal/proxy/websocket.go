@@ -52,7 +52,7 @@ func (s *Server) HandleDebugConnection(w http.ResponseWriter, r *http.Request, s
 	log.Printf("✅ Client connected to session %s debug", sessionID)
 
 	// For browserless/chrome, connect to root WebSocket (not /devtools/page/...)
-	chromeURL := sess.ConnectURL
+	chromeURL := sess.BrowserURL
 
 	log.Printf("Connecting to Chrome at %s", chromeURL)
 
@@ -70,17 +70,21 @@ func (s *Server) HandleDebugConnection(w http.ResponseWriter, r *http.Request, s
 
 	log.Printf("✅ Connected to Chrome for session %s", sessionID)
 
+	// Attached clients keep the session from idling out
+	s.sessionMgr.ClientConnected(sessionID)
+	defer s.sessionMgr.ClientDisconnected(sessionID)
+
 	// Bidirectional proxy
 	errChan := make(chan error, 2)
 
 	// Client → Chrome
 	go func() {
-		errChan <- s.proxyMessages(clientConn, chromeConn, "client→chrome")
+		errChan <- s.proxyMessages(clientConn, chromeConn, sessionID, "client→chrome")
 	}()
 
 	// Chrome → Client
 	go func() {
-		errChan <- s.proxyMessages(chromeConn, clientConn, "chrome→client")
+		errChan <- s.proxyMessages(chromeConn, clientConn, sessionID, "chrome→client")
 	}()
 
 	// Wait for either direction to close
@@ -92,7 +96,7 @@ func (s *Server) HandleDebugConnection(w http.ResponseWriter, r *http.Request, s
 	log.Printf("Client disconnected from session %s debug", sessionID)
 }
 
-func (s *Server) proxyMessages(src, dst *websocket.Conn, direction string) error {
+func (s *Server) proxyMessages(src, dst *websocket.Conn, sessionID, direction string) error {
 	for {
 		messageType, message, err := src.ReadMessage()
 		if err != nil {
@@ -106,5 +110,7 @@ func (s *Server) proxyMessages(src, dst *websocket.Conn, direction string) error
 			log.Printf("Failed to write message (%s): %v", direction, err)
 			return err
 		}
+
+		s.sessionMgr.RecordActivity(sessionID)
 	}
 }
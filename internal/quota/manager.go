@@ -0,0 +1,79 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// ErrExceeded is wrapped by errors that refuse work because a project has
+// used up one of its quotas
+var ErrExceeded = errors.New("quota exceeded")
+
+// Manager holds the quota of every project that has one
+type Manager struct {
+	quotas sync.Map // projectID -> *models.ProjectQuota
+	store  store.QuotaStore
+}
+
+// NewManager creates a quota manager and loads stored quotas
+func NewManager(quotaStore store.QuotaStore) (*Manager, error) {
+	m := &Manager{store: quotaStore}
+
+	quotas, err := quotaStore.ListQuotas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quotas: %w", err)
+	}
+	for _, quota := range quotas {
+		m.quotas.Store(quota.ProjectID, quota)
+	}
+
+	return m, nil
+}
+
+// GetQuota returns a project's quota. Projects without one get an unlimited
+// quota.
+func (m *Manager) GetQuota(projectID string) *models.ProjectQuota {
+	value, ok := m.quotas.Load(projectID)
+	if !ok {
+		return &models.ProjectQuota{ProjectID: projectID}
+	}
+	return value.(*models.ProjectQuota)
+}
+
+// SetQuota replaces a project's quota
+func (m *Manager) SetQuota(projectID string, req models.SetQuotaRequest) (*models.ProjectQuota, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("projectId is required")
+	}
+	if req.MonthlyBrowserMinutes < 0 || req.ContextStorageBytes < 0 {
+		return nil, fmt.Errorf("quotas cannot be negative")
+	}
+
+	quota := &models.ProjectQuota{
+		ProjectID:             projectID,
+		MonthlyBrowserMinutes: req.MonthlyBrowserMinutes,
+		ContextStorageBytes:   req.ContextStorageBytes,
+		UpdatedAt:             time.Now(),
+	}
+	if err := m.store.SaveQuota(quota); err != nil {
+		return nil, fmt.Errorf("failed to save quota: %w", err)
+	}
+
+	m.quotas.Store(projectID, quota)
+	return quota, nil
+}
+
+// DeleteQuota removes a project's quota so a project re-created with the same
+// ID starts unlimited
+func (m *Manager) DeleteQuota(projectID string) error {
+	if err := m.store.DeleteQuota(projectID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("failed to delete quota: %w", err)
+	}
+	m.quotas.Delete(projectID)
+	return nil
+}
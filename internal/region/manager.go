@@ -3,11 +3,20 @@ package region
 import (
 	"context"
 	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
 )
 
+// orphanGracePeriod protects containers that are still being launched, since a
+// container ID is only recorded on its session once the browser is ready
+const orphanGracePeriod = 2 * time.Minute
+
 // Region represents a geographical region
 type Region string
 
@@ -17,11 +26,27 @@ const (
 	RegionEUCentral1 Region = "eu-central-1"
 )
 
-// RegionalPool wraps a browser pool with region metadata
+// RegionalPool wraps a browser backend with region metadata
 type RegionalPool struct {
-	Region Region
-	Pool   *browser.Pool
-	Port   int
+	Region  Region
+	Backend browser.Backend
+	Port    int
+}
+
+// BackendFactory creates the browser backend for a region
+type BackendFactory func(region Region, basePort int) (browser.Backend, error)
+
+// DockerBackends runs browsers in browserless/chrome containers
+func DockerBackends(region Region, basePort int) (browser.Backend, error) {
+	return browser.NewPool(string(region), basePort)
+}
+
+// LocalBackends returns a factory running browsers as local processes of
+// binary, or of the first Chromium on PATH when binary is empty
+func LocalBackends(binary string) BackendFactory {
+	return func(region Region, basePort int) (browser.Backend, error) {
+		return browser.NewLocalBackend(string(region), binary)
+	}
 }
 
 // Manager manages browser pools across multiple regions
@@ -30,8 +55,8 @@ type Manager struct {
 	mu    sync.RWMutex
 }
 
-// NewManager creates a new multi-region manager
-func NewManager() (*Manager, error) {
+// NewManager creates a new multi-region manager with a backend per region
+func NewManager(newBackend BackendFactory) (*Manager, error) {
 	manager := &Manager{
 		pools: make(map[Region]*RegionalPool),
 	}
@@ -47,23 +72,23 @@ func NewManager() (*Manager, error) {
 	}
 
 	for _, r := range regions {
-		pool, err := browser.NewPool(string(r.region), r.port)
+		backend, err := newBackend(r.region, r.port)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create pool for %s: %w", r.region, err)
+			return nil, fmt.Errorf("failed to create backend for %s: %w", r.region, err)
 		}
 
 		manager.pools[r.region] = &RegionalPool{
-			Region: r.region,
-			Pool:   pool,
-			Port:   r.port,
+			Region:  r.region,
+			Backend: backend,
+			Port:    r.port,
 		}
 	}
 
 	return manager, nil
 }
 
-// GetPool returns the browser pool for a specific region
-func (m *Manager) GetPool(region Region) (*browser.Pool, error) {
+// GetBackend returns the browser backend for a specific region
+func (m *Manager) GetBackend(region Region) (browser.Backend, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -72,7 +97,7 @@ func (m *Manager) GetPool(region Region) (*browser.Pool, error) {
 		return nil, fmt.Errorf("unsupported region: %s", region)
 	}
 
-	return regionalPool.Pool, nil
+	return regionalPool.Backend, nil
 }
 
 // RouteSession determines the best region for a session
@@ -90,24 +115,14 @@ func (m *Manager) RouteSession(requestedRegion string) Region {
 	return RegionUSWest2
 }
 
-// LaunchBrowser launches a browser in the specified region
-func (m *Manager) LaunchBrowser(ctx context.Context, region Region, sessionID string) (*browser.BrowserInstance, error) {
-	pool, err := m.GetPool(region)
-	if err != nil {
-		return nil, err
-	}
-
-	return pool.LaunchBrowser(ctx, sessionID)
-}
-
 // LaunchBrowserWithOptions launches a browser with custom options
 func (m *Manager) LaunchBrowserWithOptions(ctx context.Context, region Region, opts browser.LaunchBrowserOptions) (*browser.BrowserInstance, error) {
-	pool, err := m.GetPool(region)
+	backend, err := m.GetBackend(region)
 	if err != nil {
 		return nil, err
 	}
 
-	return pool.LaunchBrowserWithOptions(ctx, opts)
+	return backend.Launch(ctx, opts)
 }
 
 // StopBrowser stops a browser in any region
@@ -117,7 +132,7 @@ func (m *Manager) StopBrowser(ctx context.Context, containerID string) error {
 
 	var lastErr error
 	for _, regionalPool := range m.pools {
-		err := regionalPool.Pool.StopBrowser(ctx, containerID)
+		err := regionalPool.Backend.Stop(ctx, containerID)
 		if err == nil {
 			return nil
 		}
@@ -127,13 +142,58 @@ func (m *Manager) StopBrowser(ctx context.Context, containerID string) error {
 	return lastErr
 }
 
+// InspectBrowser reports the container state of a browser in the given region
+func (m *Manager) InspectBrowser(ctx context.Context, region Region, containerID string) (*browser.ContainerState, error) {
+	backend, err := m.GetBackend(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return backend.InspectState(ctx, containerID)
+}
+
+// BrowserStats takes one reading of a browser's resource usage
+func (m *Manager) BrowserStats(ctx context.Context, region Region, containerID string) (*browser.Stats, error) {
+	reader, err := m.statsReader(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.Stats(ctx, containerID)
+}
+
+// StreamBrowserStats calls fn with readings of a browser's resource usage
+// until ctx is cancelled, the browser stops or fn returns an error
+func (m *Manager) StreamBrowserStats(ctx context.Context, region Region, containerID string, fn func(*browser.Stats) error) error {
+	reader, err := m.statsReader(region)
+	if err != nil {
+		return err
+	}
+
+	return reader.StreamStats(ctx, containerID, fn)
+}
+
+// statsReader returns the region's backend if it can report stats
+func (m *Manager) statsReader(region Region) (browser.StatsReader, error) {
+	backend, err := m.GetBackend(region)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := backend.(browser.StatsReader)
+	if !ok {
+		return nil, browser.ErrStatsUnsupported
+	}
+	return reader, nil
+}
+
 // EnsureImages ensures Chrome image is available in all regions
 func (m *Manager) EnsureImages(ctx context.Context) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for region, regionalPool := range m.pools {
-		if err := regionalPool.Pool.EnsureImage(ctx); err != nil {
+		if err := regionalPool.Backend.EnsureImage(ctx); err != nil {
 			return fmt.Errorf("failed to ensure image in %s: %w", region, err)
 		}
 	}
@@ -141,6 +201,163 @@ func (m *Manager) EnsureImages(ctx context.Context) error {
 	return nil
 }
 
+// ListManagedContainers returns the labeled containers across all regions
+func (m *Manager) ListManagedContainers(ctx context.Context) ([]browser.ManagedContainer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var all []browser.ManagedContainer
+	for region, regionalPool := range m.pools {
+		containers, err := regionalPool.Backend.ListManagedContainers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers in %s: %w", region, err)
+		}
+		all = append(all, containers...)
+	}
+
+	return all, nil
+}
+
+// ReconcileResult reports how Docker state compared to the running sessions
+type ReconcileResult struct {
+	Adopted  []*models.Session // running sessions whose container is alive
+	Missing  []*models.Session // running sessions whose container is gone
+	Orphaned []string          // containers stopped because no session owns them
+}
+
+// Reconcile compares labeled containers against the running sessions.
+// Containers with no running session are stopped; sessions are only
+// classified, since the session manager owns their state. The sessions must be
+// read before calling, so a session that starts while containers are listed
+// cannot be reported missing.
+func (m *Manager) Reconcile(ctx context.Context, sessions []*models.Session) (*ReconcileResult, error) {
+	running := make(map[string]*models.Session)
+	for _, session := range sessions {
+		if session.Status == models.StatusRunning && session.ContainerID != "" {
+			running[session.ContainerID] = session
+		}
+	}
+
+	containers, err := m.ListManagedContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	alive := make(map[string]bool)
+
+	for _, c := range containers {
+		session, owned := running[c.ContainerID]
+		if owned && c.Running {
+			alive[c.ContainerID] = true
+			result.Adopted = append(result.Adopted, session)
+			continue
+		}
+
+		if owned {
+			// Exited container for a running session: reported as missing below
+			continue
+		}
+
+		if c.Warm {
+			continue
+		}
+		// Warm containers are old when they are handed out, so the grace
+		// period runs from the assignment
+		startedAt := c.CreatedAt
+		if c.AssignedAt.After(startedAt) {
+			startedAt = c.AssignedAt
+		}
+		if time.Since(startedAt) < orphanGracePeriod {
+			continue
+		}
+
+		log.Printf("🧹 Stopping orphaned container %s (session %s, %s)", c.ContainerID[:12], c.SessionID, c.Region)
+		if err := m.StopBrowser(ctx, c.ContainerID); err != nil {
+			log.Printf("⚠️ Failed to stop orphaned container %s: %v", c.ContainerID[:12], err)
+			continue
+		}
+		result.Orphaned = append(result.Orphaned, c.ContainerID)
+	}
+
+	for containerID, session := range running {
+		if !alive[containerID] {
+			result.Missing = append(result.Missing, session)
+		}
+	}
+
+	return result, nil
+}
+
+// StartWarmPools keeps cfg.Size ready containers in every region whose
+// backend supports it, staging each region's mounts in its own subdirectory
+// of cfg.Dir
+func (m *Manager) StartWarmPools(ctx context.Context, cfg browser.WarmPoolConfig) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for region, regionalPool := range m.pools {
+		pooler, ok := regionalPool.Backend.(browser.WarmPooler)
+		if !ok {
+			if cfg.Size > 0 {
+				log.Printf("⚠️ Browser backend in %s has no warm pool, launching cold", region)
+			}
+			continue
+		}
+
+		regionCfg := cfg
+		regionCfg.Dir = filepath.Join(cfg.Dir, string(region))
+		if err := pooler.StartWarmPool(ctx, regionCfg); err != nil {
+			return fmt.Errorf("failed to start warm pool in %s: %w", region, err)
+		}
+	}
+
+	return nil
+}
+
+// DrainWarmPools removes the idle warm containers in every region
+func (m *Manager) DrainWarmPools() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, regionalPool := range m.pools {
+		if pooler, ok := regionalPool.Backend.(browser.WarmPooler); ok {
+			pooler.DrainWarmPool()
+		}
+	}
+}
+
+// WarmPoolMetrics reports the warm pool of every region that has one
+func (m *Manager) WarmPoolMetrics() []models.WarmPoolMetrics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metrics := []models.WarmPoolMetrics{}
+	for region, regionalPool := range m.pools {
+		pooler, ok := regionalPool.Backend.(browser.WarmPooler)
+		if !ok {
+			continue
+		}
+		stats, ok := pooler.WarmPoolStats()
+		if !ok {
+			continue
+		}
+		metrics = append(metrics, models.WarmPoolMetrics{
+			Region:   string(region),
+			Size:     stats.Size,
+			Idle:     stats.Idle,
+			Starting: stats.Starting,
+			Hits:     stats.Hits,
+			Misses:   stats.Misses,
+		})
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Region < metrics[j].Region
+	})
+	return metrics
+}
+
 // GetRegions returns all available regions
 func (m *Manager) GetRegions() []Region {
 	m.mu.RLock()
@@ -160,7 +377,7 @@ func (m *Manager) Close() error {
 	defer m.mu.Unlock()
 
 	for _, regionalPool := range m.pools {
-		if err := regionalPool.Pool.Close(); err != nil {
+		if err := regionalPool.Backend.Close(); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,164 @@
+package session
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// defaultDisconnectGrace is how long a session without keepAlive waits for a
+// client to reattach before ending
+const defaultDisconnectGrace = 10 * time.Second
+
+// sessionActivity tracks when a session was last used and how many CDP
+// clients are attached to it through the proxy
+type sessionActivity struct {
+	lastActive atomic.Int64 // unix nanoseconds
+	clients    atomic.Int32
+	mu         sync.Mutex
+	pendingEnd *time.Timer // ends the session unless a client reattaches
+}
+
+func (m *Manager) activityFor(sessionID string) *sessionActivity {
+	if value, ok := m.activity.Load(sessionID); ok {
+		return value.(*sessionActivity)
+	}
+
+	fresh := &sessionActivity{}
+	fresh.lastActive.Store(time.Now().UnixNano())
+	value, _ := m.activity.LoadOrStore(sessionID, fresh)
+	return value.(*sessionActivity)
+}
+
+// RecordActivity marks the session as used now. The proxy calls it for every
+// CDP message and the Puppeteer bridge for every command.
+func (m *Manager) RecordActivity(sessionID string) {
+	m.activityFor(sessionID).lastActive.Store(time.Now().UnixNano())
+}
+
+// ClientConnected registers a CDP client attached through the proxy
+func (m *Manager) ClientConnected(sessionID string) {
+	activity := m.activityFor(sessionID)
+	activity.clients.Add(1)
+	activity.lastActive.Store(time.Now().UnixNano())
+
+	activity.mu.Lock()
+	if activity.pendingEnd != nil {
+		activity.pendingEnd.Stop()
+		activity.pendingEnd = nil
+	}
+	activity.mu.Unlock()
+}
+
+// ClientDisconnected unregisters a CDP client; idle time starts counting from
+// here. A session without keepAlive ends when no client has reattached within
+// the disconnect grace period after its last client leaves.
+func (m *Manager) ClientDisconnected(sessionID string) {
+	// The session may already have ended and dropped its tracker
+	value, ok := m.activity.Load(sessionID)
+	if !ok {
+		return
+	}
+
+	activity := value.(*sessionActivity)
+	remaining := activity.clients.Add(-1)
+	activity.lastActive.Store(time.Now().UnixNano())
+	if remaining > 0 {
+		return
+	}
+
+	session, err := m.GetSession(sessionID)
+	if err != nil || session.KeepAlive {
+		return
+	}
+
+	m.mu.RLock()
+	grace := m.disconnectGrace
+	m.mu.RUnlock()
+
+	// The timer runs on its own goroutine, so teardown doesn't hold up the proxy
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	if activity.pendingEnd != nil {
+		activity.pendingEnd.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		activity.mu.Lock()
+		if activity.pendingEnd != timer || activity.clients.Load() > 0 {
+			// A client attached again in the meantime
+			activity.mu.Unlock()
+			return
+		}
+		activity.pendingEnd = nil
+		activity.mu.Unlock()
+
+		err := m.endSession(sessionID, termination{
+			status:    models.StatusCompleted,
+			endReason: models.EndReasonClientDisconnected,
+		})
+		if err == nil {
+			log.Printf("🔌 Session %s ended after its last client disconnected", sessionID[:8])
+		}
+	})
+	activity.pendingEnd = timer
+}
+
+// SetDisconnectGrace sets how long later disconnects wait for a client to
+// reattach before ending a session without keepAlive
+func (m *Manager) SetDisconnectGrace(grace time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.disconnectGrace = grace
+}
+
+// StartIdleMonitor ends sessions that have had no connected clients and no
+// commands for longer than their idleTimeout
+func (m *Manager) StartIdleMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkIdle()
+			}
+		}
+	}()
+}
+
+// checkIdle runs one idle sweep over the running sessions
+func (m *Manager) checkIdle() {
+	for _, session := range m.ListSessions(ListOptions{Status: models.StatusRunning}) {
+		if session.IdleTimeout == 0 {
+			continue
+		}
+
+		activity := m.activityFor(session.ID)
+		if activity.clients.Load() > 0 {
+			continue
+		}
+
+		idleFor := time.Since(time.Unix(0, activity.lastActive.Load()))
+		if idleFor < time.Duration(session.IdleTimeout)*time.Second {
+			continue
+		}
+
+		err := m.endSession(session.ID, termination{
+			status:    models.StatusTimedOut,
+			endReason: models.EndReasonIdleTimeout,
+		})
+		if err == nil {
+			log.Printf("💤 Session %s ended after %s idle", session.ID[:8], idleFor.Round(time.Second))
+		}
+	}
+}
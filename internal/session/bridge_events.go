@@ -0,0 +1,49 @@
+package session
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/artifacts"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// maxResponseBodyBytes caps response bodies recorded for HAR export
+const maxResponseBodyBytes = 1024 * 1024
+
+// bridgeOptions configures what the Puppeteer bridge captures
+type bridgeOptions struct {
+	RecordResponseBodies bool   `json:"recordResponseBodies"`
+	MaxBodySize          int    `json:"maxBodySize"`
+	DownloadPath         string `json:"downloadPath"`
+	Adopt                bool   `json:"adopt"` // keep the pages of an already running browser
+}
+
+// bridgeEvent is an unsolicited message from the Puppeteer bridge
+type bridgeEvent struct {
+	Event   string                   `json:"event"`
+	Entry   *models.LogEntry         `json:"entry,omitempty"`
+	Request *artifacts.NetworkRecord `json:"request,omitempty"`
+}
+
+// handleBridgeEvent routes a bridge event to the session's artifacts
+func (m *Manager) handleBridgeEvent(sessionID string, line []byte) {
+	var e bridgeEvent
+	if err := json.Unmarshal(line, &e); err != nil {
+		log.Printf("⚠️ Invalid bridge event for %s: %v", sessionID[:8], err)
+		return
+	}
+
+	switch e.Event {
+	case "log":
+		if e.Entry != nil {
+			m.artifacts.AppendLog(sessionID, *e.Entry)
+		}
+	case "network":
+		if e.Request != nil {
+			m.artifacts.AppendNetwork(sessionID, *e.Request)
+		}
+	default:
+		log.Printf("⚠️ Unknown bridge event %q for %s", e.Event, sessionID[:8])
+	}
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartDownloadJanitor deletes the downloads of sessions that ended more than
+// retention ago
+func (m *Manager) StartDownloadJanitor(ctx context.Context, interval, retention time.Duration) {
+	go func() {
+		m.pruneDownloads(retention)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.pruneDownloads(retention)
+			}
+		}
+	}()
+}
+
+// pruneDownloads runs one retention sweep over the sessions with downloads
+func (m *Manager) pruneDownloads(retention time.Duration) {
+	sessionIDs, err := m.artifacts.SessionsWithDownloads()
+	if err != nil {
+		log.Printf("⚠️ Failed to list session downloads: %v", err)
+		return
+	}
+
+	for _, id := range sessionIDs {
+		session, err := m.GetSession(id)
+		if err != nil || session.EndedAt == nil || time.Since(*session.EndedAt) < retention {
+			continue
+		}
+
+		if err := m.artifacts.DeleteDownloads(id); err != nil {
+			log.Printf("⚠️ Failed to delete downloads for session %s: %v", id[:8], err)
+			continue
+		}
+		log.Printf("🧹 Deleted downloads of session %s", id[:8])
+	}
+}
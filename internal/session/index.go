@@ -0,0 +1,268 @@
+package session
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// ListOptions narrows ListSessions. Empty fields match every session; the
+// startedAt range is inclusive of StartedAfter and exclusive of StartedBefore.
+type ListOptions struct {
+	ProjectID     string
+	Status        models.SessionStatus
+	Region        string
+	ContextID     string
+	Metadata      map[string]string // every pair must match
+	StartedAfter  time.Time
+	StartedBefore time.Time
+}
+
+// idSet is a set of session IDs
+type idSet map[string]struct{}
+
+// indexEntry is the part of a session the index needs
+type indexEntry struct {
+	id        string
+	projectID string
+	status    models.SessionStatus
+	region    string
+	contextID string
+	metadata  map[string]string
+	startedAt time.Time
+	expiresAt time.Time
+}
+
+func newIndexEntry(s *models.Session) indexEntry {
+	return indexEntry{
+		id:        s.ID,
+		projectID: s.ProjectID,
+		status:    s.Status,
+		region:    s.Region,
+		contextID: s.ContextID,
+		metadata:  s.UserMetadata,
+		startedAt: s.StartedAt,
+		expiresAt: s.ExpiresAt,
+	}
+}
+
+// startKey orders sessions by start time for range scans
+type startKey struct {
+	startedAt time.Time
+	id        string
+}
+
+func (k startKey) less(other startKey) bool {
+	if !k.startedAt.Equal(other.startedAt) {
+		return k.startedAt.Before(other.startedAt)
+	}
+	return k.id < other.id
+}
+
+// sessionIndex keeps secondary indexes over the sessions held in memory so
+// list queries touch only the sessions they can match
+type sessionIndex struct {
+	mu        sync.RWMutex
+	entries   map[string]indexEntry
+	byProject map[string]idSet
+	byStatus  map[models.SessionStatus]idSet
+	byRegion  map[string]idSet
+	byContext map[string]idSet
+	byMeta    map[metaKey]idSet
+	byStart   []startKey // sorted
+}
+
+// metaKey is one userMetadata key/value pair
+type metaKey struct {
+	key, value string
+}
+
+func newSessionIndex() *sessionIndex {
+	return &sessionIndex{
+		entries:   make(map[string]indexEntry),
+		byProject: make(map[string]idSet),
+		byStatus:  make(map[models.SessionStatus]idSet),
+		byRegion:  make(map[string]idSet),
+		byContext: make(map[string]idSet),
+		byMeta:    make(map[metaKey]idSet),
+	}
+}
+
+// update replaces a session's entries with those of its latest snapshot
+func (idx *sessionIndex) update(session *models.Session) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if previous, ok := idx.entries[session.ID]; ok {
+		idx.remove(previous)
+	}
+	idx.add(newIndexEntry(session))
+}
+
+// drop removes an evicted session from every index
+func (idx *sessionIndex) drop(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if entry, ok := idx.entries[id]; ok {
+		idx.remove(entry)
+	}
+}
+
+func (idx *sessionIndex) add(e indexEntry) {
+	idx.entries[e.id] = e
+	addID(idx.byProject, e.projectID, e.id)
+	addID(idx.byStatus, e.status, e.id)
+	addID(idx.byRegion, e.region, e.id)
+	if e.contextID != "" {
+		addID(idx.byContext, e.contextID, e.id)
+	}
+	for k, v := range e.metadata {
+		addID(idx.byMeta, metaKey{k, v}, e.id)
+	}
+
+	key := startKey{e.startedAt, e.id}
+	i := sort.Search(len(idx.byStart), func(i int) bool { return !idx.byStart[i].less(key) })
+	idx.byStart = append(idx.byStart, startKey{})
+	copy(idx.byStart[i+1:], idx.byStart[i:])
+	idx.byStart[i] = key
+}
+
+func (idx *sessionIndex) remove(e indexEntry) {
+	delete(idx.entries, e.id)
+	removeID(idx.byProject, e.projectID, e.id)
+	removeID(idx.byStatus, e.status, e.id)
+	removeID(idx.byRegion, e.region, e.id)
+	removeID(idx.byContext, e.contextID, e.id)
+	for k, v := range e.metadata {
+		removeID(idx.byMeta, metaKey{k, v}, e.id)
+	}
+
+	key := startKey{e.startedAt, e.id}
+	i := sort.Search(len(idx.byStart), func(i int) bool { return !idx.byStart[i].less(key) })
+	if i < len(idx.byStart) && idx.byStart[i] == key {
+		idx.byStart = append(idx.byStart[:i], idx.byStart[i+1:]...)
+	}
+}
+
+// lookup returns the entries of sessions matching opts, ordered by start time
+func (idx *sessionIndex) lookup(opts ListOptions) []indexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Intersect the equality filters, starting from the smallest set
+	var sets []idSet
+	if opts.ProjectID != "" {
+		sets = append(sets, idx.byProject[opts.ProjectID])
+	}
+	if opts.Status != "" {
+		sets = append(sets, idx.byStatus[opts.Status])
+	}
+	if opts.Region != "" {
+		sets = append(sets, idx.byRegion[opts.Region])
+	}
+	if opts.ContextID != "" {
+		sets = append(sets, idx.byContext[opts.ContextID])
+	}
+	for k, v := range opts.Metadata {
+		sets = append(sets, idx.byMeta[metaKey{k, v}])
+	}
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+
+	lo, hi := idx.startRange(opts.StartedAfter, opts.StartedBefore)
+
+	var entries []indexEntry
+	if len(sets) == 0 || hi-lo <= len(sets[0]) {
+		// The time range is the narrower scan and is already in order
+		for _, key := range idx.byStart[lo:hi] {
+			if inAll(sets, key.id) {
+				entries = append(entries, idx.entries[key.id])
+			}
+		}
+		return entries
+	}
+
+	for id := range sets[0] {
+		e := idx.entries[id]
+		if inRange(e.startedAt, opts.StartedAfter, opts.StartedBefore) && inAll(sets[1:], id) {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return startKey{entries[i].startedAt, entries[i].id}.less(startKey{entries[j].startedAt, entries[j].id})
+	})
+	return entries
+}
+
+// startRange returns the byStart bounds of [after, before)
+func (idx *sessionIndex) startRange(after, before time.Time) (int, int) {
+	lo, hi := 0, len(idx.byStart)
+	if !after.IsZero() {
+		lo = sort.Search(len(idx.byStart), func(i int) bool { return !idx.byStart[i].startedAt.Before(after) })
+	}
+	if !before.IsZero() {
+		hi = sort.Search(len(idx.byStart), func(i int) bool { return !idx.byStart[i].startedAt.Before(before) })
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// inRange reports whether t falls in [after, before); zero bounds are open
+func inRange(t, after, before time.Time) bool {
+	return (after.IsZero() || !t.Before(after)) && (before.IsZero() || t.Before(before))
+}
+
+func addID[K comparable](index map[K]idSet, key K, id string) {
+	set, ok := index[key]
+	if !ok {
+		set = make(idSet)
+		index[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeID[K comparable](index map[K]idSet, key K, id string) {
+	set, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+func inAll(sets []idSet, id string) bool {
+	for _, set := range sets {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks a snapshot against every filter in opts
+func (opts ListOptions) matches(s *models.Session) bool {
+	if opts.ProjectID != "" && s.ProjectID != opts.ProjectID {
+		return false
+	}
+	if opts.Status != "" && s.Status != opts.Status {
+		return false
+	}
+	if opts.Region != "" && s.Region != opts.Region {
+		return false
+	}
+	if opts.ContextID != "" && s.ContextID != opts.ContextID {
+		return false
+	}
+	for k, v := range opts.Metadata {
+		if value, ok := s.UserMetadata[k]; !ok || value != v {
+			return false
+		}
+	}
+	return inRange(s.StartedAt, opts.StartedAfter, opts.StartedBefore)
+}
@@ -4,20 +4,28 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/sync/semaphore"
 
+	"github.com/shehryarbajwa/browserbase-mini/internal/admission"
+	"github.com/shehryarbajwa/browserbase-mini/internal/artifacts"
 	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
 	contextmgr "github.com/shehryarbajwa/browserbase-mini/internal/context"
+	"github.com/shehryarbajwa/browserbase-mini/internal/events"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
 	"github.com/shehryarbajwa/browserbase-mini/internal/region"
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/internal/usage"
 	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
 )
 
@@ -29,142 +37,575 @@ type PuppeteerConnection struct {
 	stdout    io.ReadCloser
 	responses chan map[string]interface{}
 	mu        sync.Mutex
+	onCommand func() // records bridge activity for idle tracking
 }
 
 // Manager handles all session operations
 type Manager struct {
-	sessions       sync.Map
-	concurrency    map[string]*semaphore.Weighted
-	puppeteerConns sync.Map // map[sessionID]*PuppeteerConnection
-	mu             sync.RWMutex
-	regionMgr      *region.Manager
-	contextMgr     *contextmgr.Manager
+	sessions        sync.Map
+	index           *sessionIndex
+	slots           map[string]*projectSlots
+	puppeteerConns  sync.Map // map[sessionID]*PuppeteerConnection
+	timeoutRearms   sync.Map // map[sessionID]chan struct{}
+	activity        sync.Map // map[sessionID]*sessionActivity
+	quotaWarned     sync.Map // map[sessionID]bool, sessions sent a quota warning
+	statsCollectors sync.Map // map[sessionID]*statsCollector
+	admissions      sync.Map // map[sessionID]context.CancelFunc, async sessions waiting for admission
+	mu              sync.RWMutex
+	sessionMu       sync.Mutex // serializes session record updates
+	regionMgr       *region.Manager
+	contextMgr      *contextmgr.Manager
+	store           store.SessionStore
+	artifacts       *artifacts.Store
+	events          *events.Bus
+	meter           *usage.Meter
+	quotas          *quota.Manager
+	admission       *admission.Controller
+	projects        *project.Manager
+	connectBase     string // public ws:// base of this server, for connect URLs
+	bridgeScript    string // Puppeteer bridge started for each session
+	disconnectGrace time.Duration
 }
 
-// NewManager creates a new session manager
-func NewManager(regionMgr *region.Manager, ctxMgr *contextmgr.Manager) *Manager {
-	return &Manager{
-		concurrency: make(map[string]*semaphore.Weighted),
-		regionMgr:   regionMgr,
-		contextMgr:  ctxMgr,
+// NewManager creates a new session manager and restores persisted sessions.
+// connectBase is the public ws:// base URL clients reach the proxy on.
+func NewManager(regionMgr *region.Manager, ctxMgr *contextmgr.Manager, sessionStore store.SessionStore, artifactStore *artifacts.Store, meter *usage.Meter, quotas *quota.Manager, projects *project.Manager, admissionCtl *admission.Controller, connectBase string) (*Manager, error) {
+	m := &Manager{
+		slots:           make(map[string]*projectSlots),
+		index:           newSessionIndex(),
+		regionMgr:       regionMgr,
+		contextMgr:      ctxMgr,
+		store:           sessionStore,
+		artifacts:       artifactStore,
+		events:          events.NewBus(1000),
+		meter:           meter,
+		quotas:          quotas,
+		projects:        projects,
+		admission:       admissionCtl,
+		connectBase:     strings.TrimSuffix(connectBase, "/"),
+		bridgeScript:    "./internal/session/puppeteer.js",
+		disconnectGrace: defaultDisconnectGrace,
+	}
+
+	if err := m.restoreSessions(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// restoreSessions reloads session records written by a previous run
+func (m *Manager) restoreSessions() error {
+	sessions, err := m.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		if session.BrowserURL == "" && session.ConnectURL != "" {
+			// Records from before connect URLs went through the proxy
+			session.BrowserURL = session.ConnectURL
+			session.ConnectURL = m.connectURL(session.ID)
+		}
+		m.putSession(session)
+
+		if session.Status == models.StatusPending || session.Status == models.StatusStarting {
+			// The launch died with the old process; the reaper removes any container
+			m.updateSession(session.ID, func(s *models.Session) error {
+				s.Status = models.StatusError
+				s.EndReason = models.EndReasonLaunchFailed
+				s.ErrorReason = "server restarted while the session was starting"
+				return nil
+			})
+			continue
+		}
+
+		if session.Status != models.StatusRunning {
+			// Meter sessions that ended before usage was recorded; already
+			// metered ones are skipped
+			m.meterSession(session)
+			continue
+		}
+
+		// Give restored sessions a full idle window from the restart
+		m.RecordActivity(session.ID)
+
+		// The container outlived the old process, so the slot is still in use
+		m.holdSlot(session.ProjectID)
+
+		m.startStatsCollector(session)
+		go m.handleTimeout(session)
+	}
+
+	log.Printf("✓ Restored %d sessions from store", len(sessions))
+	return nil
+}
+
+// Reconcile matches running sessions against the containers Docker actually
+// has. Live sessions are re-adopted, sessions whose container disappeared are
+// marked ERROR, and unowned containers are reaped by the region manager.
+func (m *Manager) Reconcile(ctx context.Context) error {
+	// Snapshot the running sessions before Docker is listed
+	running := m.ListSessions(ListOptions{Status: models.StatusRunning})
+	result, err := m.regionMgr.Reconcile(ctx, running)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range result.Adopted {
+		if m.GetPuppeteerConnection(session.ID) != nil {
+			continue
+		}
+
+		log.Printf("🔗 Re-adopting session %s", session.ID[:8])
+		if err := m.startPuppeteerConnection(session, true); err != nil {
+			log.Printf("⚠️ Failed to reconnect Puppeteer for session %s: %v", session.ID[:8], err)
+		}
+	}
+
+	for _, session := range result.Missing {
+		if current, err := m.GetSession(session.ID); err != nil || current.Status != models.StatusRunning {
+			// Ended on its own while containers were listed
+			continue
+		}
+		log.Printf("💀 Container for session %s is gone, marking ERROR", session.ID[:8])
+		err := m.endSession(session.ID, termination{
+			status:    models.StatusError,
+			endReason: models.EndReasonBrowserExit,
+			reason:    "browser container is gone",
+		})
+		if err != nil {
+			log.Printf("⚠️ Failed to mark session %s as ERROR: %v", session.ID[:8], err)
+		}
+	}
+
+	if len(result.Orphaned) > 0 {
+		log.Printf("🧹 Reaped %d orphaned containers", len(result.Orphaned))
+	}
+
+	return nil
+}
+
+// StartHealthMonitor polls the container of every running session and moves
+// the session to ERROR when Chrome has died
+func (m *Manager) StartHealthMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+// checkHealth inspects each running session's container once
+func (m *Manager) checkHealth(ctx context.Context) {
+	for _, session := range m.ListSessions(ListOptions{Status: models.StatusRunning}) {
+		if session.ContainerID == "" {
+			continue
+		}
+
+		inspectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		state, err := m.regionMgr.InspectBrowser(inspectCtx, region.Region(session.Region), session.ContainerID)
+		cancel()
+
+		var t termination
+		switch {
+		case errors.Is(err, browser.ErrContainerNotFound):
+			t = termination{
+				status:    models.StatusError,
+				endReason: models.EndReasonBrowserExit,
+				reason:    "browser container was removed",
+			}
+		case err != nil:
+			// Docker hiccups are not evidence that Chrome died
+			log.Printf("⚠️ Health check failed for session %s: %v", session.ID[:8], err)
+			continue
+		case state.Running:
+			continue
+		case state.OOMKilled:
+			exitCode := state.ExitCode
+			t = termination{
+				status:    models.StatusError,
+				endReason: models.EndReasonOOMKilled,
+				reason:    oomReason(session.Resources),
+				exitCode:  &exitCode,
+			}
+		default:
+			exitCode := state.ExitCode
+			reason := fmt.Sprintf("browser container exited with code %d", exitCode)
+			if state.Error != "" {
+				reason = fmt.Sprintf("%s: %s", reason, state.Error)
+			}
+			t = termination{
+				status:    models.StatusError,
+				endReason: models.EndReasonBrowserExit,
+				reason:    reason,
+				exitCode:  &exitCode,
+			}
+		}
+
+		log.Printf("💀 Session %s is unhealthy: %s", session.ID[:8], t.reason)
+		if err := m.endSession(session.ID, t); err != nil {
+			log.Printf("⚠️ Failed to mark session %s as ERROR: %v", session.ID[:8], err)
+		}
 	}
 }
 
-// CreateSession creates a new browser session with optional context
+// StartReconciler runs Reconcile every interval until ctx is cancelled
+func (m *Manager) StartReconciler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Reconcile(ctx); err != nil {
+					log.Printf("⚠️ Reconciliation failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// CreateSession creates a new browser session with optional context. The
+// browser launch is detached from ctx so a client disconnect cannot abort it
+// halfway; with req.Async the session is returned PENDING and launched in the
+// background.
 func (m *Manager) CreateSession(ctx context.Context, req models.CreateSessionRequest) (*models.Session, error) {
 	// Validate request
 	if req.ProjectID == "" {
 		return nil, fmt.Errorf("projectId is required")
 	}
+	proj, err := m.projects.GetProject(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Apply defaults
 	if req.Timeout == 0 {
-		req.Timeout = 3600
+		req.Timeout = proj.DefaultTimeout
 	}
 	if req.Timeout < 60 || req.Timeout > 21600 {
 		return nil, fmt.Errorf("timeout must be between 60 and 21600 seconds")
 	}
+	if req.IdleTimeout != 0 && (req.IdleTimeout < 10 || req.IdleTimeout > 21600) {
+		return nil, fmt.Errorf("idleTimeout must be between 10 and 21600 seconds")
+	}
 	if req.Region == "" {
 		req.Region = "us-west-2"
 	}
+	switch req.Priority {
+	case "":
+		req.Priority = models.PriorityInteractive
+	case models.PriorityInteractive, models.PriorityBatch:
+	default:
+		return nil, fmt.Errorf("priority must be %s or %s", models.PriorityInteractive, models.PriorityBatch)
+	}
+	if req.WaitTimeout < 0 || req.WaitTimeout > maxWaitTimeout {
+		return nil, fmt.Errorf("waitTimeout must be between 0 and %d seconds", maxWaitTimeout)
+	}
+	if err := validateMetadata(req.UserMetadata); err != nil {
+		return nil, err
+	}
+	resources, err := sessionResources(proj, req.Resources)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check concurrency limit
-	if err := m.acquireSlot(req.ProjectID); err != nil {
+	// If contextID provided, verify it exists before taking a slot
+	if req.ContextID != "" {
+		if _, err := m.contextMgr.GetContext(req.ContextID); err != nil {
+			return nil, fmt.Errorf("context not found: %w", err)
+		}
+	}
+
+	// Projects over budget cannot start more browser time
+	if err := m.checkQuota(req); err != nil {
 		return nil, err
 	}
 
+	// Take a project slot and host capacity, queueing for them if the caller
+	// will wait. Async requests queue in the background with their record
+	// PENDING.
 	sessionID := uuid.New().String()
-	now := time.Now()
+	wait := time.Duration(req.WaitTimeout) * time.Second
+	waiter, queue, err := m.reserveSlot(proj, sessionID, wait > 0)
+	if err != nil {
+		return nil, err
+	}
+	admitted := !req.Async || wait == 0
+	if admitted {
+		if err := m.admitSession(ctx, proj, req.Priority, waiter, wait); err != nil {
+			return nil, err
+		}
+		if queue != nil {
+			queue.WaitedMs = time.Since(waiter.enqueuedAt).Milliseconds()
+		}
+	}
 
-	// Route to best region
-	targetRegion := m.regionMgr.RouteSession(req.Region)
+	now := time.Now()
 
-	// Prepare browser options
-	browserOpts := browser.LaunchBrowserOptions{
-		SessionID: sessionID,
+	// Create the session record before launching so it can be polled
+	session := &models.Session{
+		ID:                   sessionID,
+		ProjectID:            req.ProjectID,
+		Region:               string(m.regionMgr.RouteSession(req.Region)),
+		Status:               models.StatusPending,
+		StartedAt:            now,
+		ExpiresAt:            now.Add(time.Duration(req.Timeout) * time.Second),
+		Timeout:              req.Timeout,
+		IdleTimeout:          req.IdleTimeout,
+		RecordResponseBodies: req.RecordResponseBodies,
+		ContextID:            req.ContextID,
+		UserMetadata:         req.UserMetadata,
+		KeepAlive:            req.KeepAlive,
+		Priority:             req.Priority,
+		Queue:                queue,
+		Resources:            resources,
+	}
+	session.ConnectURL = m.connectURL(session.ID)
+	m.saveSession(session)
+	m.publishSession(events.SessionCreated, session)
+
+	if req.Async {
+		admitCtx, cancel := context.WithCancel(context.Background())
+		m.admissions.Store(session.ID, cancel)
+		go func() {
+			admittedNow := admitted || m.awaitAdmission(admitCtx, session, proj, waiter, wait)
+			m.admissions.Delete(session.ID)
+			cancel()
+			if !admittedNow {
+				return
+			}
+			if _, err := m.launchSession(session.ID); err != nil {
+				log.Printf("❌ Async launch failed for session %s: %v", session.ID[:8], err)
+			}
+		}()
+		return session, nil
 	}
 
-	// If contextID provided, verify it exists and try to load data
-	if req.ContextID != "" {
-		// Check if context exists
-		_, err := m.contextMgr.GetContext(req.ContextID)
-		if err != nil {
-			m.releaseSlot(req.ProjectID)
-			return nil, fmt.Errorf("context not found: %w", err)
+	return m.launchSession(session.ID)
+}
+
+// awaitAdmission waits for an async session's project slot and host
+// capacity and reports whether it may launch. Sessions that time out in a
+// queue fail like a launch that never got a browser; ctx is cancelled when
+// the session is deleted while it waits.
+func (m *Manager) awaitAdmission(ctx context.Context, session *models.Session, proj *models.Project, waiter *slotWaiter, wait time.Duration) bool {
+	if err := m.admitSession(ctx, proj, session.Priority, waiter, wait); err != nil {
+		log.Printf("⏳ Session %s left the queue: %v", session.ID[:8], err)
+		if ctx.Err() == nil {
+			m.recordLaunchFailure(session, err)
 		}
+		return false
+	}
+	if waiter == nil {
+		return true
+	}
 
-		// Try to load context data (might be empty if first use)
-		userDataDir, err := m.contextMgr.LoadContextData(req.ContextID)
-		if err != nil {
-			// Context exists but has no data yet - create fresh directory
-			userDataDir = fmt.Sprintf("/tmp/browser-context-%s", req.ContextID)
-			if err := os.MkdirAll(userDataDir, 0755); err != nil {
-				m.releaseSlot(req.ProjectID)
-				return nil, fmt.Errorf("failed to create context directory: %w", err)
-			}
+	_, err := m.updateSession(session.ID, func(s *models.Session) error {
+		if s.Status != models.StatusPending {
+			return fmt.Errorf("session was cancelled")
 		}
-		browserOpts.UserDataDir = userDataDir
+		queue := *s.Queue
+		queue.WaitedMs = time.Since(waiter.enqueuedAt).Milliseconds()
+		s.Queue = &queue
+		return nil
+	})
+	if err != nil {
+		m.releaseSlot(session.ProjectID)
+		return false
 	}
+	return true
+}
 
-	// Launch browser with or without context
-	var browserInstance *browser.BrowserInstance
-	var err error
+// Limits on caller-defined session metadata
+const (
+	maxMetadataKeys     = 32
+	maxMetadataKeyLen   = 64
+	maxMetadataValueLen = 512
+)
 
-	if browserOpts.UserDataDir != "" {
-		// Launch with context
-		browserInstance, err = m.regionMgr.LaunchBrowserWithOptions(ctx, targetRegion, browserOpts)
-	} else {
-		// Launch without context (simple)
-		browserInstance, err = m.regionMgr.LaunchBrowser(ctx, targetRegion, sessionID)
+// validateMetadata checks userMetadata against the size limits
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataKeys {
+		return fmt.Errorf("userMetadata can have at most %d keys", maxMetadataKeys)
 	}
+	for k, v := range metadata {
+		if k == "" || len(k) > maxMetadataKeyLen {
+			return fmt.Errorf("userMetadata keys must be 1 to %d characters", maxMetadataKeyLen)
+		}
+		if len(v) > maxMetadataValueLen {
+			return fmt.Errorf("userMetadata value for %q exceeds %d characters", k, maxMetadataValueLen)
+		}
+	}
+	return nil
+}
 
+// launchSession starts the browser for a PENDING session and moves it through
+// STARTING to RUNNING, or to ERROR if the launch fails. The caller's slot is
+// released if the session was cancelled first.
+func (m *Manager) launchSession(id string) (*models.Session, error) {
+	session, err := m.updateSession(id, func(s *models.Session) error {
+		if s.Status != models.StatusPending {
+			return fmt.Errorf("session was cancelled before its browser launched")
+		}
+		s.Status = models.StatusStarting
+		return nil
+	})
 	if err != nil {
-		m.releaseSlot(req.ProjectID)
+		if cancelled, getErr := m.GetSession(id); getErr == nil {
+			m.releaseSlot(cancelled.ProjectID)
+		}
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	browserInstance, err := m.startBrowser(ctx, session)
+	if err != nil {
+		m.failLaunch(session, err)
 		return nil, fmt.Errorf("failed to launch browser: %w", err)
 	}
 
-	// Create session
-	session := &models.Session{
-		ID:          sessionID,
-		ProjectID:   req.ProjectID,
-		Region:      string(targetRegion),
-		Status:      models.StatusRunning,
-		StartedAt:   now,
-		ExpiresAt:   now.Add(time.Duration(req.Timeout) * time.Second),
-		Timeout:     req.Timeout,
-		ConnectURL:  browserInstance.ConnectURL,
-		ContainerID: browserInstance.ContainerID,
-		ContextID:   req.ContextID,
-		UserDataDir: browserInstance.UserDataDir,
-	}
-
-	// Store session
-	m.sessions.Store(session.ID, session)
+	// The timeout runs from when the browser is actually usable
+	now := time.Now()
+	session, err = m.updateSession(id, func(s *models.Session) error {
+		s.Status = models.StatusRunning
+		s.StartedAt = now
+		s.ExpiresAt = now.Add(time.Duration(s.Timeout) * time.Second)
+		s.BrowserURL = browserInstance.ConnectURL
+		s.ContainerID = browserInstance.ContainerID
+		s.UserDataDir = browserInstance.UserDataDir
+		s.DownloadPath = browserInstance.DownloadPath
+		s.UploadPath = browserInstance.UploadPath
+
+		// Follow resource usage so it can be summarised when the session
+		// ends. Starting under the update means an end can never see the
+		// session RUNNING without its collector.
+		m.startStatsCollector(s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Start persistent Puppeteer connection
-	if err := m.startPuppeteerConnection(session); err != nil {
+	if err := m.startPuppeteerConnection(session, false); err != nil {
 		log.Printf("⚠️ Failed to start Puppeteer connection: %v", err)
 		// Don't fail session creation, just log it
 	}
 
+	// Idle time is measured from the moment the browser became usable
+	m.RecordActivity(session.ID)
+
 	// Start timeout handler
 	go m.handleTimeout(session)
 
 	return session, nil
 }
 
-// startPuppeteerConnection creates a persistent Node.js process for this session
-func (m *Manager) startPuppeteerConnection(session *models.Session) error {
-	// Path to puppeteer script
-	scriptPath := "./internal/session/puppeteer.js"
+// startBrowser launches the container for a session, loading its context first
+func (m *Manager) startBrowser(ctx context.Context, session *models.Session) (*browser.BrowserInstance, error) {
+	targetRegion := region.Region(session.Region)
+
+	// Downloads and uploads live with the session's artifacts so they outlive the container
+	downloadDir, err := m.artifacts.DownloadDir(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	uploadDir, err := m.artifacts.UploadDir(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.ContextID == "" {
+		// Launch without context (simple)
+		return m.regionMgr.LaunchBrowserWithOptions(ctx, targetRegion, browser.LaunchBrowserOptions{
+			SessionID:   session.ID,
+			DownloadDir: downloadDir,
+			UploadDir:   uploadDir,
+			Resources:   launchResources(session.Resources),
+		})
+	}
+
+	// Try to load context data (might be empty if first use)
+	userDataDir, err := m.contextMgr.LoadContextData(session.ContextID)
+	if err != nil {
+		// Context exists but has no data yet - create fresh directory
+		userDataDir = fmt.Sprintf("/tmp/browser-context-%s", session.ContextID)
+		if err := os.MkdirAll(userDataDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create context directory: %w", err)
+		}
+	}
+
+	// Launch with context
+	return m.regionMgr.LaunchBrowserWithOptions(ctx, targetRegion, browser.LaunchBrowserOptions{
+		SessionID:   session.ID,
+		UserDataDir: userDataDir,
+		DownloadDir: downloadDir,
+		UploadDir:   uploadDir,
+		Resources:   launchResources(session.Resources),
+	})
+}
+
+// failLaunch records a launch failure on the session and frees its slot
+func (m *Manager) failLaunch(session *models.Session, launchErr error) {
+	m.recordLaunchFailure(session, launchErr)
+	m.releaseSlot(session.ProjectID)
+}
+
+// recordLaunchFailure moves a session that never got a browser to ERROR
+func (m *Manager) recordLaunchFailure(session *models.Session, launchErr error) {
+	_, err := m.updateSession(session.ID, func(s *models.Session) error {
+		now := time.Now()
+		s.Status = models.StatusError
+		s.EndReason = models.EndReasonLaunchFailed
+		s.ErrorReason = launchErr.Error()
+		s.EndedAt = &now
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to record launch failure for session %s: %v", session.ID[:8], err)
+	}
+}
+
+// startPuppeteerConnection creates a persistent Node.js process for this session.
+// With adopt the browser was already running, so its open pages are kept.
+func (m *Manager) startPuppeteerConnection(session *models.Session, adopt bool) error {
+	m.mu.RLock()
+	scriptPath := m.bridgeScript
+	m.mu.RUnlock()
 
 	// Check if file exists
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 		return fmt.Errorf("puppeteer script not found at %s", scriptPath)
 	}
 
+	// Per-session capture settings travel as a JSON argument
+	options, err := json.Marshal(bridgeOptions{
+		RecordResponseBodies: session.RecordResponseBodies,
+		MaxBodySize:          maxResponseBodyBytes,
+		DownloadPath:         downloadPath(session),
+		Adopt:                adopt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode bridge options: %w", err)
+	}
+
 	// START NODE PROCESS with script file
-	cmd := exec.Command("node", scriptPath, session.ConnectURL)
+	cmd := exec.Command("node", scriptPath, session.BrowserURL, string(options))
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -189,6 +630,7 @@ func (m *Manager) startPuppeteerConnection(session *models.Session) error {
 		Stdin:     stdin,
 		stdout:    stdout,
 		responses: make(chan map[string]interface{}, 10),
+		onCommand: func() { m.RecordActivity(session.ID) },
 	}
 
 	// READ STDOUT → JSON messages
@@ -201,15 +643,23 @@ func (m *Manager) startPuppeteerConnection(session *models.Session) error {
 
 		for scanner.Scan() {
 			line := scanner.Text()
-			log.Printf("PUPPETEER[%s] OUT: %s", session.ID[:8], line)
 
 			var result map[string]interface{}
 			if json.Unmarshal([]byte(line), &result) == nil {
+				// Unsolicited events are not command responses
+				if _, isEvent := result["event"]; isEvent {
+					m.handleBridgeEvent(session.ID, []byte(line))
+					continue
+				}
+
+				log.Printf("PUPPETEER[%s] OUT: %s", session.ID[:8], line)
 				select {
 				case conn.responses <- result:
 				default:
 					log.Printf("⚠️ Response buffer full for %s", session.ID)
 				}
+			} else {
+				log.Printf("PUPPETEER[%s] OUT: %s", session.ID[:8], line)
 			}
 		}
 
@@ -242,6 +692,33 @@ func (m *Manager) startPuppeteerConnection(session *models.Session) error {
 	return nil
 }
 
+// closePuppeteerConnection asks the bridge to disconnect and kills it if it
+// does not exit on its own, which happens when Chrome has already died
+func (m *Manager) closePuppeteerConnection(sessionID string) {
+	value, ok := m.puppeteerConns.LoadAndDelete(sessionID)
+	if !ok {
+		return
+	}
+	conn := value.(*PuppeteerConnection)
+
+	log.Printf("🔌 Closing Puppeteer connection for session %s", sessionID[:8])
+	conn.SendCommand(map[string]interface{}{"action": "close"}, 5*time.Second)
+
+	done := make(chan struct{})
+	go func() {
+		conn.Process.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		log.Printf("⚠️ Puppeteer for session %s did not exit, killing it", sessionID[:8])
+		conn.Process.Process.Kill()
+		<-done
+	}
+}
+
 // GetPuppeteerConnection retrieves the persistent Puppeteer connection for a session
 func (m *Manager) GetPuppeteerConnection(sessionID string) *PuppeteerConnection {
 	value, ok := m.puppeteerConns.Load(sessionID)
@@ -252,10 +729,14 @@ func (m *Manager) GetPuppeteerConnection(sessionID string) *PuppeteerConnection
 }
 
 // SendCommand sends a command to the Puppeteer process and waits for response
-func (conn *PuppeteerConnection) SendCommand(cmd map[string]string, timeout time.Duration) (map[string]interface{}, error) {
+func (conn *PuppeteerConnection) SendCommand(cmd map[string]interface{}, timeout time.Duration) (map[string]interface{}, error) {
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
 
+	if conn.onCommand != nil && cmd["action"] != "close" {
+		conn.onCommand()
+	}
+
 	cmdJSON, err := json.Marshal(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal command: %w", err)
@@ -281,57 +762,281 @@ func (conn *PuppeteerConnection) SendCommand(cmd map[string]string, timeout time
 // GetSession retrieves a session by ID
 func (m *Manager) GetSession(id string) (*models.Session, error) {
 	value, ok := m.sessions.Load(id)
-	if !ok {
+	if ok {
+		return value.(*models.Session), nil
+	}
+
+	// Sessions that ended long ago are evicted from memory but stay in the store
+	session, err := m.store.Load(id)
+	if err != nil {
 		return nil, fmt.Errorf("session not found")
 	}
-	return value.(*models.Session), nil
+	return session, nil
 }
 
-// ListSessions returns all sessions for a project, optionally filtered by status
-func (m *Manager) ListSessions(projectID string, status models.SessionStatus) []*models.Session {
-	var sessions []*models.Session
+// saveSession publishes a session snapshot and writes it to the durable store
+func (m *Manager) saveSession(session *models.Session) {
+	m.putSession(session)
 
-	m.sessions.Range(func(key, value interface{}) bool {
-		session := value.(*models.Session)
+	if err := m.store.Save(session); err != nil {
+		log.Printf("⚠️ Failed to persist session %s: %v", session.ID[:8], err)
+	}
+}
 
-		if projectID != "" && session.ProjectID != projectID {
-			return true
-		}
+// putSession replaces the in-memory snapshot and keeps the list indexes in step
+func (m *Manager) putSession(session *models.Session) {
+	m.sessions.Store(session.ID, session)
+	m.index.update(session)
+}
+
+// updateSession applies fn to a copy of the stored session and persists the
+// result. Snapshots handed out by GetSession are never mutated in place.
+func (m *Manager) updateSession(id string, fn func(session *models.Session) error) (*models.Session, error) {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+
+	current, err := m.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	if err := fn(&updated); err != nil {
+		return nil, err
+	}
+
+	m.saveSession(&updated)
 
-		if status != "" && session.Status != status {
-			return true
+	if updated.Status != current.Status {
+		if current.Status == models.StatusRunning {
+			m.meterSession(&updated)
 		}
+		if eventType, ok := statusEvents[updated.Status]; ok {
+			m.publishSession(eventType, &updated)
+		}
+	}
 
-		sessions = append(sessions, session)
-		return true
+	return &updated, nil
+}
+
+// statusEvents maps the status transitions that are published on the bus
+var statusEvents = map[models.SessionStatus]events.Type{
+	models.StatusRunning:   events.SessionRunning,
+	models.StatusCompleted: events.SessionCompleted,
+	models.StatusTimedOut:  events.SessionTimedOut,
+	models.StatusError:     events.SessionError,
+}
+
+// publishSession emits a session event carrying a snapshot of the session
+func (m *Manager) publishSession(eventType events.Type, session *models.Session) {
+	m.events.Publish(events.Event{
+		Type:      eventType,
+		ProjectID: session.ProjectID,
+		SessionID: session.ID,
+		Data:      session,
 	})
+}
+
+// connectURL is the proxy endpoint clients use for a session. It stays the
+// same for the session's whole life, so clients can reconnect through it.
+func (m *Manager) connectURL(sessionID string) string {
+	return fmt.Sprintf("%s/v1/sessions/%s/ws", m.connectBase, sessionID)
+}
+
+// meterSession records the browser time of an ended session
+func (m *Manager) meterSession(session *models.Session) {
+	if err := m.meter.Record(session); err != nil {
+		log.Printf("⚠️ Failed to meter session %s: %v", session.ID[:8], err)
+	}
+}
+
+// Usage returns the meter holding per-project browser time
+func (m *Manager) Usage() *usage.Meter {
+	return m.meter
+}
+
+// Artifacts returns the store holding per-session logs and files
+func (m *Manager) Artifacts() *artifacts.Store {
+	return m.artifacts
+}
+
+// Projects returns the registry of projects sessions are created in
+func (m *Manager) Projects() *project.Manager {
+	return m.projects
+}
+
+// SetBridgeScript sets the Puppeteer bridge script used by later sessions.
+// The default is relative to the repository root.
+func (m *Manager) SetBridgeScript(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bridgeScript = path
+}
+
+// Events returns the bus that carries session lifecycle events
+func (m *Manager) Events() *events.Bus {
+	return m.events
+}
+
+// ListSessions returns the sessions matching opts, oldest first
+func (m *Manager) ListSessions(opts ListOptions) []*models.Session {
+	sessions := []*models.Session{}
+
+	for _, entry := range m.index.lookup(opts) {
+		session, err := m.GetSession(entry.id)
+		if err != nil || !opts.matches(session) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
 
 	return sessions
 }
 
+// UpdateSession changes the timeouts of a live session or releases it
+func (m *Manager) UpdateSession(id string, req models.UpdateSessionRequest) (*models.Session, error) {
+	switch req.Status {
+	case "":
+	case models.StatusRequestRelease:
+		if req.Timeout != nil || req.ExtendBy != 0 || req.IdleTimeout != nil {
+			return nil, fmt.Errorf("status REQUEST_RELEASE cannot be combined with other changes")
+		}
+		if err := m.DeleteSession(id); err != nil {
+			return nil, err
+		}
+		return m.GetSession(id)
+	default:
+		return nil, fmt.Errorf("status can only be updated to %s", models.StatusRequestRelease)
+	}
+
+	if req.Timeout == nil && req.ExtendBy == 0 && req.IdleTimeout == nil {
+		return nil, fmt.Errorf("nothing to update")
+	}
+	if req.Timeout != nil && req.ExtendBy != 0 {
+		return nil, fmt.Errorf("timeout and extendBy are mutually exclusive")
+	}
+	if req.ExtendBy < 0 {
+		return nil, fmt.Errorf("extendBy must be positive")
+	}
+	if req.IdleTimeout != nil && *req.IdleTimeout != 0 && (*req.IdleTimeout < 10 || *req.IdleTimeout > 21600) {
+		return nil, fmt.Errorf("idleTimeout must be between 10 and 21600 seconds")
+	}
+
+	session, err := m.updateSession(id, func(s *models.Session) error {
+		switch s.Status {
+		case models.StatusPending, models.StatusStarting, models.StatusRunning:
+		default:
+			return fmt.Errorf("session has already ended")
+		}
+
+		if req.IdleTimeout != nil {
+			s.IdleTimeout = *req.IdleTimeout
+		}
+		if req.Timeout == nil && req.ExtendBy == 0 {
+			return nil
+		}
+
+		timeout := s.Timeout + req.ExtendBy
+		if req.Timeout != nil {
+			timeout = *req.Timeout
+		}
+		if timeout < 60 || timeout > 21600 {
+			return fmt.Errorf("timeout must be between 60 and 21600 seconds")
+		}
+
+		expiresAt := s.StartedAt.Add(time.Duration(timeout) * time.Second)
+		if !expiresAt.After(time.Now()) {
+			return fmt.Errorf("timeout of %d seconds has already elapsed", timeout)
+		}
+
+		s.Timeout = timeout
+		s.ExpiresAt = expiresAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.rearmTimeout(id)
+	return session, nil
+}
+
 // DeleteSession marks a session as completed and optionally saves context
 func (m *Manager) DeleteSession(id string) error {
-	session, err := m.GetSession(id)
+	return m.endSession(id, termination{
+		status:    models.StatusCompleted,
+		endReason: models.EndReasonRequested,
+	})
+}
+
+// termination describes the terminal state a session is moved to
+type termination struct {
+	status    models.SessionStatus
+	endReason models.EndReason
+	reason    string
+	exitCode  *int
+}
+
+// endSession moves a running or queued session to a terminal status and
+// tears down its browser. Only the first caller wins, so a timeout racing a
+// delete is safe.
+func (m *Manager) endSession(id string, t termination) error {
+	queued := false
+	session, err := m.updateSession(id, func(s *models.Session) error {
+		switch s.Status {
+		case models.StatusRunning:
+		case models.StatusPending:
+			queued = true
+		case models.StatusStarting:
+			return fmt.Errorf("session is still starting")
+		default:
+			return fmt.Errorf("session is not running")
+		}
+		now := time.Now()
+		s.Status = t.status
+		s.EndReason = t.endReason
+		s.ErrorReason = t.reason
+		s.ExitCode = t.exitCode
+		s.EndedAt = &now
+		s.Stats = m.statsSummary(id)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if session.Status != models.StatusRunning {
-		return fmt.Errorf("session is not running")
+	if queued {
+		// Leave the slot and admission queues. A session that was already
+		// admitted frees its slot when its launch finds it ended.
+		if cancel, ok := m.admissions.LoadAndDelete(id); ok {
+			cancel.(context.CancelFunc)()
+		}
+		m.artifacts.CloseSession(id)
+		return nil
 	}
 
+	m.stopStatsCollector(id)
+
+	// Let the timeout goroutine exit instead of waiting for the old expiry
+	m.rearmTimeout(id)
+	m.activity.Delete(id)
+	m.quotaWarned.Delete(id)
+
 	// Close Puppeteer connection first
-	if conn := m.GetPuppeteerConnection(id); conn != nil {
-		log.Printf("🔌 Closing Puppeteer connection for session %s", id[:8])
-		conn.SendCommand(map[string]string{"action": "close"}, 5*time.Second)
-		conn.Process.Wait()
-		m.puppeteerConns.Delete(id)
-	}
+	m.closePuppeteerConnection(id)
 
 	// Save context if this session was using one
 	if session.ContextID != "" && session.UserDataDir != "" {
 		if err := m.saveSessionContext(session); err != nil {
 			fmt.Printf("Warning: failed to save context %s: %v\n", session.ContextID, err)
+		} else if savedContext, err := m.contextMgr.GetContext(session.ContextID); err == nil {
+			m.events.Publish(events.Event{
+				Type:      events.ContextSaved,
+				ProjectID: session.ProjectID,
+				SessionID: session.ID,
+				Data:      *savedContext,
+			})
 		}
 	}
 
@@ -345,13 +1050,12 @@ func (m *Manager) DeleteSession(id string) error {
 		}
 	}
 
-	// Update status
-	session.Status = models.StatusCompleted
-	m.sessions.Store(id, session)
-
 	// Release concurrency slot
 	m.releaseSlot(session.ProjectID)
 
+	// End live log streams; the stored artifacts stay readable
+	m.artifacts.CloseSession(id)
+
 	return nil
 }
 
@@ -366,77 +1070,54 @@ func (m *Manager) saveSessionContext(session *models.Session) error {
 	return m.contextMgr.UpdateContext(session.ContextID)
 }
 
-// acquireSlot tries to acquire a concurrency slot for the project
-func (m *Manager) acquireSlot(projectID string) error {
-	m.mu.Lock()
-	sem, exists := m.concurrency[projectID]
-	if !exists {
-		sem = semaphore.NewWeighted(10)
-		m.concurrency[projectID] = sem
-	}
-	m.mu.Unlock()
-
-	if !sem.TryAcquire(1) {
-		return fmt.Errorf("concurrency limit reached for project %s", projectID)
-	}
-
-	return nil
-}
+// handleTimeout automatically terminates a session once it reaches ExpiresAt.
+// UpdateSession can move ExpiresAt, so the timer is re-armed from the current
+// record whenever it fires early or is poked through timeoutRearms.
+func (m *Manager) handleTimeout(session *models.Session) {
+	rearm := make(chan struct{}, 1)
+	m.timeoutRearms.Store(session.ID, rearm)
+	defer m.timeoutRearms.Delete(session.ID)
 
-// releaseSlot releases a concurrency slot for the project
-func (m *Manager) releaseSlot(projectID string) {
-	m.mu.RLock()
-	sem := m.concurrency[projectID]
-	m.mu.RUnlock()
+	timer := time.NewTimer(time.Until(session.ExpiresAt))
+	defer timer.Stop()
 
-	if sem != nil {
-		sem.Release(1)
-	}
-}
+	for {
+		select {
+		case <-timer.C:
+		case <-rearm:
+		}
 
-// handleTimeout automatically terminates a session after its timeout
-func (m *Manager) handleTimeout(session *models.Session) {
-	timer := time.NewTimer(time.Duration(session.Timeout) * time.Second)
-	defer timer.Stop()
+		current, err := m.GetSession(session.ID)
+		if err != nil || current.Status != models.StatusRunning {
+			return
+		}
 
-	<-timer.C
+		if remaining := time.Until(current.ExpiresAt); remaining > 0 {
+			timer.Reset(remaining)
+			continue
+		}
 
-	current, err := m.GetSession(session.ID)
-	if err != nil {
+		err = m.endSession(session.ID, termination{
+			status:    models.StatusTimedOut,
+			endReason: models.EndReasonTimeout,
+		})
+		if err == nil {
+			log.Printf("⏱️ Session %s timed out", session.ID[:8])
+		}
 		return
 	}
+}
 
-	if current.Status != models.StatusRunning {
+// rearmTimeout wakes the timeout goroutine so it re-reads the session
+func (m *Manager) rearmTimeout(sessionID string) {
+	value, ok := m.timeoutRearms.Load(sessionID)
+	if !ok {
 		return
 	}
 
-	// Close Puppeteer connection
-	if conn := m.GetPuppeteerConnection(current.ID); conn != nil {
-		log.Printf("🔌 Closing Puppeteer connection for timed out session %s", current.ID[:8])
-		conn.SendCommand(map[string]string{"action": "close"}, 5*time.Second)
-		conn.Process.Wait()
-		m.puppeteerConns.Delete(current.ID)
-	}
-
-	// Save context if this session was using one
-	if current.ContextID != "" && current.UserDataDir != "" {
-		if err := m.saveSessionContext(current); err != nil {
-			fmt.Printf("Warning: failed to save context %s on timeout: %v\n", current.ContextID, err)
-		}
-	}
-
-	// Stop the browser container
-	if current.ContainerID != "" {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		if err := m.regionMgr.StopBrowser(ctx, current.ContainerID); err != nil {
-			fmt.Printf("Warning: failed to stop container %s on timeout: %v\n", current.ContainerID, err)
-		}
+	select {
+	case value.(chan struct{}) <- struct{}{}:
+	default:
+		// A wake-up is already pending
 	}
-
-	// Update status
-	current.Status = models.StatusTimedOut
-	m.sessions.Store(current.ID, current)
-	m.releaseSlot(current.ProjectID)
 }
@@ -0,0 +1,129 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// SortField is a session timestamp lists can be ordered by
+type SortField string
+
+const (
+	SortStartedAt SortField = "startedAt"
+	SortExpiresAt SortField = "expiresAt"
+)
+
+// MaxPageSize bounds the limit of a paginated list
+const MaxPageSize = 500
+
+// PageOptions orders a session list and selects one page of it. A zero Limit
+// returns every remaining session.
+type PageOptions struct {
+	SortBy     SortField
+	Descending bool
+	Limit      int
+	Cursor     string // NextCursor of the previous page
+}
+
+// pageCursor marks the last session of a page. It carries the ordering so a
+// cursor cannot be replayed against a different sort.
+type pageCursor struct {
+	SortBy     SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Time       time.Time `json:"t"`
+	ID         string    `json:"id"`
+}
+
+// ListSessionsPage returns one page of the sessions matching opts in the
+// requested order. Sessions that share a timestamp are ordered by ID, so pages
+// never skip or repeat a session.
+func (m *Manager) ListSessionsPage(opts ListOptions, page PageOptions) (*models.SessionPage, error) {
+	if page.SortBy == "" {
+		page.SortBy = SortStartedAt
+	}
+	if page.SortBy != SortStartedAt && page.SortBy != SortExpiresAt {
+		return nil, fmt.Errorf("sort must be %s or %s", SortStartedAt, SortExpiresAt)
+	}
+	if page.Limit < 0 || page.Limit > MaxPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+
+	entries := m.index.lookup(opts)
+
+	sortTime := func(e indexEntry) time.Time {
+		if page.SortBy == SortExpiresAt {
+			return e.expiresAt
+		}
+		return e.startedAt
+	}
+	before := func(t time.Time, id string, other indexEntry) bool {
+		key, otherKey := startKey{t, id}, startKey{sortTime(other), other.id}
+		if page.Descending {
+			return otherKey.less(key)
+		}
+		return key.less(otherKey)
+	}
+
+	// lookup returns start order; anything else needs a re-sort
+	if page.SortBy != SortStartedAt || page.Descending {
+		sort.Slice(entries, func(i, j int) bool {
+			return before(sortTime(entries[i]), entries[i].id, entries[j])
+		})
+	}
+
+	start := 0
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != page.SortBy || cursor.Descending != page.Descending {
+			return nil, fmt.Errorf("cursor was issued for a different sort order")
+		}
+		start = sort.Search(len(entries), func(i int) bool {
+			return before(cursor.Time, cursor.ID, entries[i])
+		})
+	}
+
+	result := &models.SessionPage{Data: []*models.Session{}}
+	for i := start; i < len(entries); i++ {
+		if page.Limit > 0 && len(result.Data) == page.Limit {
+			last := entries[i-1]
+			result.NextCursor = encodeCursor(pageCursor{
+				SortBy:     page.SortBy,
+				Descending: page.Descending,
+				Time:       sortTime(last),
+				ID:         last.id,
+			})
+			break
+		}
+
+		session, err := m.GetSession(entries[i].id)
+		if err != nil || !opts.matches(session) {
+			continue
+		}
+		result.Data = append(result.Data, session)
+	}
+
+	return result, nil
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (pageCursor, error) {
+	var cursor pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID == "" {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	return cursor, nil
+}
@@ -3,6 +3,177 @@ const readline = require('readline');
 
 let browser, page;
 let screenshotLock = false;
+const watchedTargets = new WeakSet();
+
+// Per-session capture settings passed by the Go side as a JSON argument
+const bridgeOptions = JSON.parse(process.argv[3] || '{}');
+
+// Events are unsolicited stdout lines tagged with "event" so the Go side can
+// tell them apart from command responses
+function emit(event, payload) {
+    console.log(JSON.stringify({ event, ...payload }));
+}
+
+function consoleLevel(type) {
+    if (type === 'debug') return 'debug';
+    if (type === 'warning') return 'warning';
+    if (type === 'error' || type === 'assert') return 'error';
+    return 'info';
+}
+
+function logLevel(level) {
+    return level === 'verbose' ? 'debug' : level;
+}
+
+function remoteObjectText(arg) {
+    if (arg.value !== undefined) {
+        return typeof arg.value === 'string' ? arg.value : JSON.stringify(arg.value);
+    }
+    return arg.description || arg.unserializableValue || arg.type;
+}
+
+function cdpTime(wallTime) {
+    return new Date(wallTime * 1000).toISOString();
+}
+
+// networkRecord converts a tracked request into the record the Go side stores
+function networkRecord(req, endTimestamp) {
+    const res = req.response || {};
+    return {
+        startedDateTime: cdpTime(req.wallTime),
+        time: Math.max(0, (endTimestamp - req.timestamp) * 1000),
+        method: req.request.method,
+        url: req.request.url,
+        httpVersion: res.protocol ? res.protocol.toUpperCase() : 'HTTP/1.1',
+        requestHeaders: req.request.headers,
+        postData: req.request.postData,
+        status: res.status || 0,
+        statusText: res.statusText || '',
+        responseHeaders: res.headers || {},
+        mimeType: res.mimeType || '',
+        bodySize: req.encodedDataLength || 0,
+        timing: res.timing ? {
+            dnsStart: res.timing.dnsStart,
+            dnsEnd: res.timing.dnsEnd,
+            connectStart: res.timing.connectStart,
+            connectEnd: res.timing.connectEnd,
+            sslStart: res.timing.sslStart,
+            sslEnd: res.timing.sslEnd,
+            sendStart: res.timing.sendStart,
+            sendEnd: res.timing.sendEnd,
+            receiveHeadersEnd: res.timing.receiveHeadersEnd,
+        } : undefined,
+        remoteIPAddress: res.remoteIPAddress,
+    };
+}
+
+// watchNetwork reports every finished request on a page as a 'network'
+// event, optionally with its response body
+async function watchNetwork(client) {
+    const requests = new Map();
+
+    client.on('Network.requestWillBeSent', (e) => {
+        // A redirect reuses the request ID; the hop that redirected is done
+        const previous = requests.get(e.requestId);
+        if (previous && e.redirectResponse) {
+            previous.response = e.redirectResponse;
+            emit('network', { request: networkRecord(previous, e.timestamp) });
+        }
+        requests.set(e.requestId, {
+            request: e.request,
+            timestamp: e.timestamp,
+            wallTime: e.wallTime,
+        });
+    });
+
+    client.on('Network.responseReceived', (e) => {
+        const req = requests.get(e.requestId);
+        if (req) req.response = e.response;
+    });
+
+    client.on('Network.loadingFinished', async (e) => {
+        const req = requests.get(e.requestId);
+        if (!req) return;
+        requests.delete(e.requestId);
+        req.encodedDataLength = e.encodedDataLength;
+
+        const record = networkRecord(req, e.timestamp);
+        if (bridgeOptions.recordResponseBodies && e.encodedDataLength <= bridgeOptions.maxBodySize) {
+            try {
+                const { body, base64Encoded } = await client.send('Network.getResponseBody', { requestId: e.requestId });
+                record.body = body;
+                record.base64Encoded = base64Encoded;
+            } catch (err) {
+                // Bodies of redirects and evicted resources are unavailable
+            }
+        }
+        emit('network', { request: record });
+    });
+
+    client.on('Network.loadingFailed', (e) => {
+        const req = requests.get(e.requestId);
+        if (!req) return;
+        requests.delete(e.requestId);
+
+        const record = networkRecord(req, e.timestamp);
+        record.error = e.errorText;
+        emit('network', { request: record });
+    });
+
+    await client.send('Network.enable');
+}
+
+// watchTarget records console output, uncaught exceptions, browser log
+// entries and network activity for a page through its own CDP session
+async function watchTarget(target) {
+    if (target.type() !== 'page' || watchedTargets.has(target)) return;
+    watchedTargets.add(target);
+
+    try {
+        const client = await target.createCDPSession();
+
+        client.on('Runtime.consoleAPICalled', (e) => {
+            const frame = e.stackTrace && e.stackTrace.callFrames[0];
+            emit('log', { entry: {
+                timestamp: new Date(e.timestamp).toISOString(),
+                level: consoleLevel(e.type),
+                source: 'console',
+                text: e.args.map(remoteObjectText).join(' '),
+                url: frame ? frame.url : undefined,
+                line: frame ? frame.lineNumber + 1 : undefined,
+            }});
+        });
+
+        client.on('Runtime.exceptionThrown', (e) => {
+            const details = e.exceptionDetails;
+            emit('log', { entry: {
+                timestamp: new Date(e.timestamp).toISOString(),
+                level: 'error',
+                source: 'exception',
+                text: (details.exception && details.exception.description) || details.text,
+                url: details.url,
+                line: details.lineNumber + 1,
+            }});
+        });
+
+        client.on('Log.entryAdded', ({ entry }) => {
+            emit('log', { entry: {
+                timestamp: new Date(entry.timestamp).toISOString(),
+                level: logLevel(entry.level),
+                source: entry.source,
+                text: entry.text,
+                url: entry.url,
+                line: entry.lineNumber !== undefined ? entry.lineNumber + 1 : undefined,
+            }});
+        });
+
+        await client.send('Runtime.enable');
+        await client.send('Log.enable');
+        await watchNetwork(client);
+    } catch (err) {
+        console.error("⚠️ Failed to watch target:", err.message);
+    }
+}
 
 (async () => {
     try {
@@ -22,21 +193,44 @@ let screenshotLock = false;
         });
         console.error("✅ Connected to browser");
 
-        // Close all existing pages to avoid stale references
+        // Capture logs from every page, including tabs clients open later
+        browser.on('targetcreated', watchTarget);
+
+        // Save downloads to the mounted per-session directory
+        if (bridgeOptions.downloadPath) {
+            const browserClient = await browser.target().createCDPSession();
+            await browserClient.send('Browser.setDownloadBehavior', {
+                behavior: 'allow',
+                downloadPath: bridgeOptions.downloadPath,
+            });
+            console.error("✅ Downloads go to", bridgeOptions.downloadPath);
+        }
+
         const existingPages = await browser.pages();
         console.error("Found", existingPages.length, "existing pages");
-        for (const p of existingPages) {
-            try {
-                await p.close();
-                console.error("Closed existing page:", p.url());
-            } catch (err) {
-                console.error("Error closing page:", err.message);
+
+        if (bridgeOptions.adopt) {
+            // The session outlived a restart or a bridge reconnect; its tabs
+            // belong to the client, so keep them and drive the first one
+            for (const p of existingPages) {
+                await watchTarget(p.target());
+            }
+            page = existingPages[0] || await browser.newPage();
+            console.error("Adopted page:", page.url());
+        } else {
+            // A fresh browser's startup pages would only be stale references
+            for (const p of existingPages) {
+                try {
+                    await p.close();
+                    console.error("Closed existing page:", p.url());
+                } catch (err) {
+                    console.error("Error closing page:", err.message);
+                }
             }
-        }
 
-        // Create a fresh page
-        page = await browser.newPage();
-        console.error("Created new page:", page.url());
+            page = await browser.newPage();
+            console.error("Created new page:", page.url());
+        }
 
         console.log(JSON.stringify({ status: "ready" }));
         console.error("Sent 'ready' signal");
@@ -173,6 +367,25 @@ let screenshotLock = false;
                         console.error("✅ Sent blank PNG response");
                     }
                 }
+                else if (cmd.action === "setInputFiles") {
+                    console.error("📎 Setting input files on", cmd.selector);
+
+                    const element = await page.$(cmd.selector);
+                    if (!element) {
+                        throw new Error(`no element matches selector ${cmd.selector}`);
+                    }
+
+                    // Object IDs only resolve in the page's own CDP session,
+                    // so let Puppeteer attach the files through it
+                    try {
+                        await element.uploadFile(...cmd.files);
+                    } finally {
+                        await element.dispose();
+                    }
+
+                    console.log(JSON.stringify({ status: "inputFilesSet", count: cmd.files.length }));
+                    console.error("✅ Set", cmd.files.length, "input files");
+                }
                 else if (cmd.action === "close") {
                     console.error("Closing browser...");
                     await browser.disconnect();
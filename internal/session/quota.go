@@ -0,0 +1,146 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/events"
+	"github.com/shehryarbajwa/browserbase-mini/internal/quota"
+	"github.com/shehryarbajwa/browserbase-mini/internal/usage"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// quotaWarningRatio is the share of the monthly browser-minute quota at
+// which running sessions are warned
+const quotaWarningRatio = 0.9
+
+// checkQuota refuses a new session when its project has used up a quota.
+// Context storage only counts against sessions that will save a context.
+func (m *Manager) checkQuota(req models.CreateSessionRequest) error {
+	limits := m.quotas.GetQuota(req.ProjectID)
+
+	if limits.MonthlyBrowserMinutes > 0 {
+		used := m.browserTimeThisMonth(req.ProjectID, time.Now())
+		if used >= time.Duration(limits.MonthlyBrowserMinutes)*time.Minute {
+			return fmt.Errorf("%w: project %s has used its monthly quota of %d browser minutes",
+				quota.ErrExceeded, req.ProjectID, limits.MonthlyBrowserMinutes)
+		}
+	}
+
+	if req.ContextID != "" && limits.ContextStorageBytes > 0 {
+		if m.contextMgr.StorageUsed(req.ProjectID) >= limits.ContextStorageBytes {
+			return fmt.Errorf("%w: project %s has used its context storage quota of %d bytes",
+				quota.ErrExceeded, req.ProjectID, limits.ContextStorageBytes)
+		}
+	}
+
+	return nil
+}
+
+// browserTimeThisMonth is a project's metered browser time this UTC month
+// plus the time its running sessions have spent in the month so far
+func (m *Manager) browserTimeThisMonth(projectID string, now time.Time) time.Duration {
+	used := m.meter.MonthToDate(projectID, now)
+
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, session := range m.ListSessions(ListOptions{ProjectID: projectID, Status: models.StatusRunning}) {
+		start := session.StartedAt
+		if start.Before(monthStart) {
+			start = monthStart
+		}
+		if now.After(start) {
+			used += now.Sub(start)
+		}
+	}
+
+	return used
+}
+
+// QuotaStatus reports a project's quota and its usage against it
+func (m *Manager) QuotaStatus(projectID string) *models.QuotaStatus {
+	return &models.QuotaStatus{
+		ProjectQuota:       *m.quotas.GetQuota(projectID),
+		BrowserMinutesUsed: usage.Minutes(m.browserTimeThisMonth(projectID, time.Now())),
+		ContextStorageUsed: m.contextMgr.StorageUsed(projectID),
+	}
+}
+
+// Quotas returns the manager holding per-project quotas
+func (m *Manager) Quotas() *quota.Manager {
+	return m.quotas
+}
+
+// StartQuotaMonitor checks running sessions against their project's monthly
+// browser-minute quota. Sessions are warned once with a quota_warning event
+// when the project nears its quota and ended when it is used up.
+func (m *Manager) StartQuotaMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.checkQuotas()
+			}
+		}
+	}()
+}
+
+// checkQuotas runs one pass over the projects with running sessions
+func (m *Manager) checkQuotas() {
+	running := make(map[string][]*models.Session)
+	for _, session := range m.ListSessions(ListOptions{Status: models.StatusRunning}) {
+		running[session.ProjectID] = append(running[session.ProjectID], session)
+	}
+
+	now := time.Now()
+	for projectID, sessions := range running {
+		limits := m.quotas.GetQuota(projectID)
+		if limits.MonthlyBrowserMinutes == 0 {
+			continue
+		}
+
+		limit := time.Duration(limits.MonthlyBrowserMinutes) * time.Minute
+		used := m.browserTimeThisMonth(projectID, now)
+
+		switch {
+		case used >= limit:
+			log.Printf("🚫 Project %s used its monthly quota of %d browser minutes, ending %d sessions",
+				projectID, limits.MonthlyBrowserMinutes, len(sessions))
+			for _, session := range sessions {
+				err := m.endSession(session.ID, termination{
+					status:    models.StatusTimedOut,
+					endReason: models.EndReasonQuotaExhausted,
+					reason:    fmt.Sprintf("monthly quota of %d browser minutes used up", limits.MonthlyBrowserMinutes),
+				})
+				if err != nil {
+					log.Printf("⚠️ Failed to end session %s over quota: %v", session.ID[:8], err)
+				}
+			}
+
+		case float64(used) >= quotaWarningRatio*float64(limit):
+			status := &models.QuotaStatus{
+				ProjectQuota:       *limits,
+				BrowserMinutesUsed: usage.Minutes(used),
+				ContextStorageUsed: m.contextMgr.StorageUsed(projectID),
+			}
+			for _, session := range sessions {
+				if _, warned := m.quotaWarned.LoadOrStore(session.ID, true); warned {
+					continue
+				}
+				m.events.Publish(events.Event{
+					Type:      events.QuotaWarning,
+					ProjectID: projectID,
+					SessionID: session.ID,
+					Data:      status,
+				})
+			}
+		}
+	}
+}
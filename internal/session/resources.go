@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/internal/project"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// sessionResources overlays a request's resource overrides on the project's
+// defaults, field by field
+func sessionResources(proj *models.Project, override *models.ResourceLimits) (*models.ResourceLimits, error) {
+	limits := proj.Resources
+	if override != nil {
+		if override.CPUShares != 0 {
+			limits.CPUShares = override.CPUShares
+		}
+		if override.CPUs != 0 {
+			limits.CPUs = override.CPUs
+		}
+		if override.MemoryMB != 0 {
+			limits.MemoryMB = override.MemoryMB
+		}
+		if override.ShmSizeMB != 0 {
+			limits.ShmSizeMB = override.ShmSizeMB
+		}
+		if override.PidsLimit != 0 {
+			limits.PidsLimit = override.PidsLimit
+		}
+	}
+
+	if err := project.ValidateResources(limits); err != nil {
+		return nil, fmt.Errorf("invalid resources: %w", err)
+	}
+	return &limits, nil
+}
+
+// launchResources converts a session's limits to what the backend applies.
+// Sessions from before limits existed run unconstrained.
+func launchResources(limits *models.ResourceLimits) browser.ResourceLimits {
+	if limits == nil {
+		return browser.ResourceLimits{}
+	}
+
+	return browser.ResourceLimits{
+		CPUShares: limits.CPUShares,
+		CPUs:      limits.CPUs,
+		Memory:    limits.MemoryMB << 20,
+		ShmSize:   limits.ShmSizeMB << 20,
+		PidsLimit: limits.PidsLimit,
+	}
+}
+
+// oomReason describes an OOM kill for the session's errorReason
+func oomReason(limits *models.ResourceLimits) string {
+	if limits == nil || limits.MemoryMB == 0 {
+		return "browser was killed for running out of memory"
+	}
+	return fmt.Sprintf("browser exceeded its %dMB memory limit", limits.MemoryMB)
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// StartEvictor drops sessions that ended more than retention ago from memory
+// and the list indexes. Their records stay in the durable store, so GetSession
+// still returns them.
+func (m *Manager) StartEvictor(ctx context.Context, interval, retention time.Duration) {
+	go func() {
+		m.evictSessions(retention)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.evictSessions(retention)
+			}
+		}
+	}()
+}
+
+// evictSessions runs one eviction sweep over the in-memory sessions
+func (m *Manager) evictSessions(retention time.Duration) {
+	evicted := 0
+
+	m.sessions.Range(func(key, value interface{}) bool {
+		session := value.(*models.Session)
+
+		switch session.Status {
+		case models.StatusCompleted, models.StatusError, models.StatusTimedOut:
+		default:
+			return true
+		}
+
+		// Records from before endedAt was tracked fall back to their expiry
+		endedAt := session.ExpiresAt
+		if session.EndedAt != nil {
+			endedAt = *session.EndedAt
+		}
+		if time.Since(endedAt) < retention {
+			return true
+		}
+
+		// Don't drop a snapshot an update is about to replace
+		m.sessionMu.Lock()
+		if m.sessions.CompareAndDelete(session.ID, session) {
+			m.index.drop(session.ID)
+			evicted++
+		}
+		m.sessionMu.Unlock()
+		return true
+	})
+
+	if evicted > 0 {
+		log.Printf("🗄️ Evicted %d ended sessions from memory", evicted)
+	}
+}
@@ -0,0 +1,258 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/admission"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// maxWaitTimeout bounds how long a create request may queue for a slot
+const maxWaitTimeout = 600
+
+// projectSlots tracks a project's concurrency slots and the create requests
+// queued for one, oldest first
+type projectSlots struct {
+	inUse    int
+	queue    []*slotWaiter
+	timeouts int64
+}
+
+// slotWaiter is a create request queued for a concurrency slot
+type slotWaiter struct {
+	sessionID  string // set for async requests, which already have a record
+	enqueuedAt time.Time
+	granted    bool
+	ready      chan struct{} // closed once the slot is granted
+}
+
+// slotsFor returns a project's slot state; m.mu must be held
+func (m *Manager) slotsFor(projectID string) *projectSlots {
+	ps, ok := m.slots[projectID]
+	if !ok {
+		ps = &projectSlots{}
+		m.slots[projectID] = ps
+	}
+	return ps
+}
+
+// reserveSlot takes a free concurrency slot for the project. When none is
+// free and queueing is allowed the request joins the project's FIFO queue and
+// the returned waiter must be passed to waitForSlot; a nil waiter means the
+// slot is already held. The ceiling is read from the project on every call,
+// so resizing a project applies without a restart.
+func (m *Manager) reserveSlot(proj *models.Project, sessionID string, queue bool) (*slotWaiter, *models.QueueInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps := m.slotsFor(proj.ID)
+	if len(ps.queue) == 0 && ps.inUse < proj.Concurrency {
+		ps.inUse++
+		return nil, nil, nil
+	}
+	if !queue {
+		return nil, nil, fmt.Errorf("concurrency limit of %d reached for project %s", proj.Concurrency, proj.ID)
+	}
+
+	w := &slotWaiter{
+		sessionID:  sessionID,
+		enqueuedAt: time.Now(),
+		ready:      make(chan struct{}),
+	}
+	ps.queue = append(ps.queue, w)
+
+	return w, &models.QueueInfo{Position: len(ps.queue), Depth: len(ps.queue)}, nil
+}
+
+// waitForSlot blocks until the waiter is granted a slot, timeout passes or
+// ctx is cancelled. A request that gives up leaves the queue.
+func (m *Manager) waitForSlot(ctx context.Context, projectID string, w *slotWaiter, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// Slots only free up on release, so poll to pick up a raised ceiling
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		var cause error
+		timedOut := false
+		select {
+		case <-w.ready:
+			return nil
+		case <-ticker.C:
+			m.mu.Lock()
+			m.dispatchSlots(projectID)
+			m.mu.Unlock()
+			continue
+		case <-timer.C:
+			timedOut = true
+			cause = fmt.Errorf("timed out after %s waiting for a concurrency slot in project %s", timeout, projectID)
+		case <-ctx.Done():
+			cause = fmt.Errorf("stopped waiting for a concurrency slot in project %s: %w", projectID, ctx.Err())
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if w.granted {
+			return nil
+		}
+
+		ps := m.slotsFor(projectID)
+		for i, queued := range ps.queue {
+			if queued == w {
+				ps.queue = append(ps.queue[:i], ps.queue[i+1:]...)
+				break
+			}
+		}
+		if timedOut {
+			ps.timeouts++
+		}
+		return cause
+	}
+}
+
+// dispatchSlots hands free slots to queued requests in FIFO order; m.mu must
+// be held. Requests queued for a project that no longer exists wait out their
+// timeout.
+func (m *Manager) dispatchSlots(projectID string) {
+	ps := m.slotsFor(projectID)
+	if len(ps.queue) == 0 {
+		return
+	}
+
+	proj, err := m.projects.GetProject(projectID)
+	if err != nil {
+		return
+	}
+
+	for len(ps.queue) > 0 && ps.inUse < proj.Concurrency {
+		w := ps.queue[0]
+		ps.queue = ps.queue[1:]
+		ps.inUse++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// admitSession finishes taking a slot for a new session: it waits out the
+// project queue if the request joined it, then takes host capacity from the
+// admission controller, all within wait. Nothing is held on failure.
+func (m *Manager) admitSession(ctx context.Context, proj *models.Project, priority models.Priority, waiter *slotWaiter, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	if waiter != nil {
+		if err := m.waitForSlot(ctx, proj.ID, waiter, wait); err != nil {
+			return err
+		}
+	}
+
+	ticket := admission.Ticket{
+		ProjectID: proj.ID,
+		Weight:    proj.Weight,
+		Priority:  priority,
+	}
+	if err := m.admission.Acquire(ctx, ticket, time.Until(deadline)); err != nil {
+		m.releaseProjectSlot(proj.ID)
+		return err
+	}
+	return nil
+}
+
+// holdSlot takes a project slot and host capacity regardless of either
+// ceiling, for sessions whose browser is already running
+func (m *Manager) holdSlot(projectID string) {
+	m.mu.Lock()
+	m.slotsFor(projectID).inUse++
+	m.mu.Unlock()
+
+	m.admission.Hold(projectID)
+}
+
+// releaseSlot frees a session's project slot and host capacity
+func (m *Manager) releaseSlot(projectID string) {
+	m.releaseProjectSlot(projectID)
+	m.admission.Release(projectID)
+}
+
+// releaseProjectSlot frees a project's concurrency slot for the next queued
+// request
+func (m *Manager) releaseProjectSlot(projectID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps := m.slotsFor(projectID)
+	if ps.inUse > 0 {
+		ps.inUse--
+	}
+	m.dispatchSlots(projectID)
+}
+
+// QueuePosition reports where a queued async session stands in its
+// project's queue, or nil when it is not queued
+func (m *Manager) QueuePosition(session *models.Session) *models.QueueInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps, ok := m.slots[session.ProjectID]
+	if !ok {
+		return nil
+	}
+	for i, w := range ps.queue {
+		if w.sessionID == session.ID {
+			return &models.QueueInfo{
+				Position: i + 1,
+				Depth:    len(ps.queue),
+				WaitedMs: time.Since(w.enqueuedAt).Milliseconds(),
+			}
+		}
+	}
+	return nil
+}
+
+// Metrics reports concurrency slot use and queueing for every project that
+// has created sessions since the server started
+func (m *Manager) Metrics() *models.Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := &models.Metrics{
+		Admission: m.admission.Metrics(),
+		WarmPools: m.regionMgr.WarmPoolMetrics(),
+		Projects:  []models.ProjectMetrics{},
+	}
+	now := time.Now()
+
+	for projectID, ps := range m.slots {
+		entry := models.ProjectMetrics{
+			ProjectID:     projectID,
+			ActiveSlots:   ps.inUse,
+			QueueDepth:    len(ps.queue),
+			QueueTimeouts: ps.timeouts,
+			Queue:         []models.QueuedRequest{},
+		}
+		if proj, err := m.projects.GetProject(projectID); err == nil {
+			entry.Concurrency = proj.Concurrency
+			entry.Weight = proj.Weight
+		}
+		for i, w := range ps.queue {
+			entry.Queue = append(entry.Queue, models.QueuedRequest{
+				Position:  i + 1,
+				SessionID: w.sessionID,
+				WaitingMs: now.Sub(w.enqueuedAt).Milliseconds(),
+			})
+		}
+		if len(ps.queue) > 0 {
+			entry.OldestWaitMs = now.Sub(ps.queue[0].enqueuedAt).Milliseconds()
+		}
+		metrics.Projects = append(metrics.Projects, entry)
+	}
+
+	sort.Slice(metrics.Projects, func(i, j int) bool {
+		return metrics.Projects[i].ProjectID < metrics.Projects[j].ProjectID
+	})
+	return metrics
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/internal/region"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// statsRetryDelay is how long a collector waits before reopening a stats
+// stream that broke while its session was still running
+const statsRetryDelay = 5 * time.Second
+
+// statsCollector follows a running session's resource usage so it can be
+// rolled into the session record when the session ends
+type statsCollector struct {
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	summary  models.SessionStats
+	cpuTotal float64
+}
+
+// record folds one reading into the summary. Network and block IO are
+// cumulative, so the latest reading holds the totals.
+func (c *statsCollector) record(stats *browser.Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := &c.summary
+	s.Samples++
+	c.cpuTotal += stats.CPUPercent
+	s.AvgCPUPercent = c.cpuTotal / float64(s.Samples)
+	if stats.CPUPercent > s.PeakCPUPercent {
+		s.PeakCPUPercent = stats.CPUPercent
+	}
+	if stats.MemoryUsage > s.PeakMemoryBytes {
+		s.PeakMemoryBytes = stats.MemoryUsage
+	}
+	s.NetworkRxBytes = stats.NetworkRx
+	s.NetworkTxBytes = stats.NetworkTx
+	s.BlockReadBytes = stats.BlockRead
+	s.BlockWriteBytes = stats.BlockWrite
+	s.LastSampleAt = stats.Time
+}
+
+// snapshot returns a copy of the summary, or nil before the first reading
+func (c *statsCollector) snapshot() *models.SessionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.summary.Samples == 0 {
+		return nil
+	}
+	summary := c.summary
+	return &summary
+}
+
+// startStatsCollector follows the stats of a running session's browser until
+// stopStatsCollector is called. Sessions restored after a restart only
+// summarise their usage from then on.
+func (m *Manager) startStatsCollector(session *models.Session) {
+	ctx, cancel := context.WithCancel(context.Background())
+	collector := &statsCollector{cancel: cancel}
+	if previous, loaded := m.statsCollectors.Swap(session.ID, collector); loaded {
+		previous.(*statsCollector).cancel()
+	}
+
+	go func() {
+		for {
+			err := m.regionMgr.StreamBrowserStats(ctx, region.Region(session.Region), session.ContainerID, func(stats *browser.Stats) error {
+				collector.record(stats)
+				return nil
+			})
+			if errors.Is(err, browser.ErrStatsUnsupported) || errors.Is(err, browser.ErrContainerNotFound) {
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Stats stream for session %s broke: %v", session.ID[:8], err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(statsRetryDelay):
+			}
+		}
+	}()
+}
+
+// statsSummary returns a session's rolled-up stats so far, or nil
+func (m *Manager) statsSummary(sessionID string) *models.SessionStats {
+	value, ok := m.statsCollectors.Load(sessionID)
+	if !ok {
+		return nil
+	}
+	return value.(*statsCollector).snapshot()
+}
+
+// stopStatsCollector stops following a session's stats
+func (m *Manager) stopStatsCollector(sessionID string) {
+	if value, ok := m.statsCollectors.LoadAndDelete(sessionID); ok {
+		value.(*statsCollector).cancel()
+	}
+}
+
+// SessionStats takes a live reading of a running session's browser
+func (m *Manager) SessionStats(ctx context.Context, session *models.Session) (*models.ResourceStats, error) {
+	stats, err := m.regionMgr.BrowserStats(ctx, region.Region(session.Region), session.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+	return resourceStats(stats), nil
+}
+
+// StreamSessionStats calls fn with live readings of a session's browser until
+// ctx is cancelled, the browser stops or fn returns an error
+func (m *Manager) StreamSessionStats(ctx context.Context, session *models.Session, fn func(*models.ResourceStats) error) error {
+	return m.regionMgr.StreamBrowserStats(ctx, region.Region(session.Region), session.ContainerID, func(stats *browser.Stats) error {
+		return fn(resourceStats(stats))
+	})
+}
+
+// resourceStats converts a backend reading for the API
+func resourceStats(stats *browser.Stats) *models.ResourceStats {
+	return &models.ResourceStats{
+		Timestamp:        stats.Time,
+		CPUPercent:       stats.CPUPercent,
+		MemoryBytes:      stats.MemoryUsage,
+		MemoryLimitBytes: stats.MemoryLimit,
+		NetworkRxBytes:   stats.NetworkRx,
+		NetworkTxBytes:   stats.NetworkTx,
+		BlockReadBytes:   stats.BlockRead,
+		BlockWriteBytes:  stats.BlockWrite,
+	}
+}
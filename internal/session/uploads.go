@@ -0,0 +1,96 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/browser"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// SaveUpload stores a file for a live session and returns where its browser
+// sees it
+func (m *Manager) SaveUpload(id, name string, r io.Reader) (*models.Upload, error) {
+	session, err := m.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case models.StatusPending, models.StatusStarting, models.StatusRunning:
+	default:
+		return nil, fmt.Errorf("session has already ended")
+	}
+
+	size, err := m.artifacts.SaveUpload(id, name, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Upload{
+		Name: name,
+		Path: path.Join(uploadPath(session), name),
+		Size: size,
+	}, nil
+}
+
+// uploadPath is where a session's browser sees its uploads. Sessions that
+// have not launched yet assume the container mount.
+func uploadPath(session *models.Session) string {
+	if session.UploadPath != "" {
+		return session.UploadPath
+	}
+	return browser.UploadPath
+}
+
+// downloadPath is where a session's browser writes its downloads
+func downloadPath(session *models.Session) string {
+	if session.DownloadPath != "" {
+		return session.DownloadPath
+	}
+	return browser.DownloadPath
+}
+
+// SetInputFiles attaches previously uploaded files to the file input matching
+// selector on the session's page
+func (m *Manager) SetInputFiles(id string, req models.SetInputFilesRequest) error {
+	if req.Selector == "" {
+		return fmt.Errorf("selector is required")
+	}
+	if len(req.Files) == 0 {
+		return fmt.Errorf("files is required")
+	}
+
+	session, err := m.GetSession(id)
+	if err != nil {
+		return err
+	}
+	if session.Status != models.StatusRunning {
+		return fmt.Errorf("session is not running")
+	}
+
+	// Only files in the upload directory can be attached. Paths handed out
+	// before launch use the container mount and are mapped to the browser's.
+	files := make([]string, 0, len(req.Files))
+	for _, file := range req.Files {
+		dir := path.Dir(file)
+		if (dir != uploadPath(session) && dir != browser.UploadPath) || !m.artifacts.HasUpload(id, path.Base(file)) {
+			return fmt.Errorf("file %s has not been uploaded to this session", file)
+		}
+		files = append(files, path.Join(uploadPath(session), path.Base(file)))
+	}
+
+	conn := m.GetPuppeteerConnection(id)
+	if conn == nil {
+		return fmt.Errorf("no Puppeteer connection available")
+	}
+
+	_, err = conn.SendCommand(map[string]interface{}{
+		"action":   "setInputFiles",
+		"selector": req.Selector,
+		"files":    files,
+	}, 15*time.Second)
+	return err
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// quarantineDir is the subdirectory of each store directory that holds
+// records that could not be decoded
+const quarantineDir = "quarantine"
+
+// writeJSON marshals v and writes it atomically to path
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// readJSONDir calls decode with the contents of every .json file in dir.
+// Files decode rejects are quarantined and unreadable ones skipped, so one bad
+// record cannot keep the server from starting.
+func readJSONDir(dir string, decode func(data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Printf("⚠️ Skipping %s: %v", filepath.Join(dir, entry.Name()), err)
+			continue
+		}
+		if err := decode(data); err != nil {
+			quarantine(dir, entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// quarantine moves an undecodable record out of dir for inspection
+func quarantine(dir, name string, cause error) {
+	path := filepath.Join(dir, name)
+	target := filepath.Join(dir, quarantineDir)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		log.Printf("⚠️ Skipping undecodable %s: %v (quarantine failed: %v)", path, cause, err)
+		return
+	}
+	if err := os.Rename(path, filepath.Join(target, name)); err != nil {
+		log.Printf("⚠️ Skipping undecodable %s: %v (quarantine failed: %v)", path, cause, err)
+		return
+	}
+	log.Printf("⚠️ Quarantined undecodable %s: %v", path, cause)
+}
+
+// writeFileAtomic writes data to a temp file and renames it into place so a
+// crash mid-write never leaves a truncated record behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+	return nil
+}
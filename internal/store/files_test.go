@@ -0,0 +1,39 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+func TestListProjectsQuarantinesCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileProjectStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveProject(&models.Project{ID: "good"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"id": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := s.ListProjects()
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != "good" {
+		t.Fatalf("listed %+v, want only the good project", projects)
+	}
+	if _, err := os.Stat(filepath.Join(dir, quarantineDir, "bad.json")); err != nil {
+		t.Errorf("corrupt project was not quarantined: %v", err)
+	}
+
+	// The quarantined file stays out of later listings
+	if projects, err := s.ListProjects(); err != nil || len(projects) != 1 {
+		t.Errorf("second listing = %+v, %v", projects, err)
+	}
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// ProjectStore persists the project registry
+type ProjectStore interface {
+	SaveProject(project *models.Project) error
+	DeleteProject(id string) error
+	ListProjects() ([]*models.Project, error)
+}
+
+// FileProjectStore keeps one JSON file per project on local disk
+type FileProjectStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileProjectStore creates a file-backed project store rooted at dir
+func NewFileProjectStore(dir string) (*FileProjectStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project store directory: %w", err)
+	}
+
+	return &FileProjectStore{dir: dir}, nil
+}
+
+// SaveProject writes a project record
+func (s *FileProjectStore) SaveProject(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeJSON(filepath.Join(s.dir, filepath.Base(project.ID)+".json"), project)
+}
+
+// DeleteProject removes a project record
+func (s *FileProjectStore) DeleteProject(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ListProjects returns every registered project
+func (s *FileProjectStore) ListProjects() ([]*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var projects []*models.Project
+	err := readJSONDir(s.dir, func(data []byte) error {
+		project := &models.Project{}
+		if err := json.Unmarshal(data, project); err != nil {
+			return err
+		}
+		projects = append(projects, project)
+		return nil
+	})
+	return projects, err
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// QuotaStore persists per-project quotas
+type QuotaStore interface {
+	SaveQuota(quota *models.ProjectQuota) error
+	DeleteQuota(projectID string) error
+	ListQuotas() ([]*models.ProjectQuota, error)
+}
+
+// FileQuotaStore keeps one JSON file per project quota on local disk
+type FileQuotaStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileQuotaStore creates a file-backed quota store rooted at dir
+func NewFileQuotaStore(dir string) (*FileQuotaStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create quota store directory: %w", err)
+	}
+
+	return &FileQuotaStore{dir: dir}, nil
+}
+
+// SaveQuota writes a project's quota
+func (s *FileQuotaStore) SaveQuota(quota *models.ProjectQuota) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeJSON(filepath.Join(s.dir, filepath.Base(quota.ProjectID)+".json"), quota)
+}
+
+// DeleteQuota removes a project's quota
+func (s *FileQuotaStore) DeleteQuota(projectID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(projectID)+".json"))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ListQuotas returns every stored quota
+func (s *FileQuotaStore) ListQuotas() ([]*models.ProjectQuota, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var quotas []*models.ProjectQuota
+	err := readJSONDir(s.dir, func(data []byte) error {
+		quota := &models.ProjectQuota{}
+		if err := json.Unmarshal(data, quota); err != nil {
+			return err
+		}
+		quotas = append(quotas, quota)
+		return nil
+	})
+	return quotas, err
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// ErrNotFound is returned when a record does not exist in the store
+var ErrNotFound = errors.New("not found")
+
+// SessionStore persists session records across server restarts
+type SessionStore interface {
+	Save(session *models.Session) error
+	Load(id string) (*models.Session, error)
+	List() ([]*models.Session, error)
+	Delete(id string) error
+}
+
+// storedSession includes the internal fields that models.Session hides from the API
+type storedSession struct {
+	*models.Session
+	ContainerID string `json:"containerId"`
+	UserDataDir string `json:"userDataDir"`
+	BrowserURL  string `json:"browserUrl,omitempty"`
+	// Browser-side file paths; empty in records from before backends varied
+	DownloadPath string `json:"downloadPath,omitempty"`
+	UploadPath   string `json:"uploadPath,omitempty"`
+}
+
+// FileSessionStore keeps one JSON file per session on local disk
+type FileSessionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileSessionStore creates a file-backed session store rooted at dir
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create session store directory: %w", err)
+	}
+
+	return &FileSessionStore{
+		dir: dir,
+	}, nil
+}
+
+// Save writes a session record, replacing any previous version
+func (s *FileSessionStore) Save(session *models.Session) error {
+	data, err := json.Marshal(storedSession{
+		Session:      session,
+		ContainerID:  session.ContainerID,
+		UserDataDir:  session.UserDataDir,
+		BrowserURL:   session.BrowserURL,
+		DownloadPath: session.DownloadPath,
+		UploadPath:   session.UploadPath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.path(session.ID), data)
+}
+
+// Load reads a single session record
+func (s *FileSessionStore) Load(id string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(s.path(id))
+}
+
+// List returns every session record in the store. Records that cannot be
+// decoded are moved to the quarantine subdirectory.
+func (s *FileSessionStore) List() ([]*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []*models.Session{}
+	err := readJSONDir(s.dir, func(data []byte) error {
+		session, err := decodeSession(data)
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, session)
+		return nil
+	})
+	return sessions, err
+}
+
+// Delete removes a session record
+func (s *FileSessionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSessionStore) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
+
+func (s *FileSessionStore) load(path string) (*models.Session, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	session, err := decodeSession(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+	return session, nil
+}
+
+// decodeSession restores a session from its stored record
+func decodeSession(data []byte) (*models.Session, error) {
+	record := storedSession{Session: &models.Session{}}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	record.Session.ContainerID = record.ContainerID
+	record.Session.UserDataDir = record.UserDataDir
+	record.Session.BrowserURL = record.BrowserURL
+	record.Session.DownloadPath = record.DownloadPath
+	record.Session.UploadPath = record.UploadPath
+	return record.Session, nil
+}
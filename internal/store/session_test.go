@@ -0,0 +1,38 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+func TestListQuarantinesCorruptRecords(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(&models.Session{ID: "good", Status: models.StatusCompleted}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"id": "bad", "status": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "good" {
+		t.Fatalf("listed %+v, want only the good record", sessions)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, quarantineDir, "bad.json")); err != nil {
+		t.Errorf("corrupt record was not quarantined: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.json")); !os.IsNotExist(err) {
+		t.Errorf("corrupt record is still in the store: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// UsageStore persists the append-only ledger of metered sessions
+type UsageStore interface {
+	Append(record models.UsageRecord) error
+	List() ([]models.UsageRecord, error)
+}
+
+// FileUsageStore keeps the usage ledger as one NDJSON file on local disk
+type FileUsageStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileUsageStore creates a file-backed usage ledger in dir
+func NewFileUsageStore(dir string) (*FileUsageStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create usage store directory: %w", err)
+	}
+
+	return &FileUsageStore{
+		path: filepath.Join(dir, "ledger.ndjson"),
+	}, nil
+}
+
+// Append adds a record to the end of the ledger and syncs it to disk
+func (s *FileUsageStore) Append(record models.UsageRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage record: %w", err)
+	}
+	return file.Sync()
+}
+
+// List returns every record in the ledger in the order it was written. A
+// torn last line from a crash mid-append is skipped.
+func (s *FileUsageStore) List() ([]models.UsageRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var records []models.UsageRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record models.UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// WebhookStore persists webhook registrations and their delivery log
+type WebhookStore interface {
+	SaveWebhook(webhook *models.Webhook) error
+	DeleteWebhook(id string) error
+	ListWebhooks() ([]*models.Webhook, error)
+	SaveDelivery(delivery *models.WebhookDelivery) error
+	LoadDelivery(id string) (*models.WebhookDelivery, error)
+	ListDeliveries() ([]*models.WebhookDelivery, error)
+	DeleteDelivery(id string) error
+	SaveCursor(eventID uint64) error
+	LoadCursor() (uint64, error)
+}
+
+// FileWebhookStore keeps webhooks and deliveries as JSON files on local disk
+type FileWebhookStore struct {
+	webhooksDir   string
+	deliveriesDir string
+	cursorPath    string
+	mu            sync.Mutex
+}
+
+// NewFileWebhookStore creates a file-backed webhook store rooted at dir
+func NewFileWebhookStore(dir string) (*FileWebhookStore, error) {
+	s := &FileWebhookStore{
+		webhooksDir:   filepath.Join(dir, "hooks"),
+		deliveriesDir: filepath.Join(dir, "deliveries"),
+		cursorPath:    filepath.Join(dir, "cursor"),
+	}
+
+	for _, d := range []string{s.webhooksDir, s.deliveriesDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create webhook store directory: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// SaveWebhook writes a webhook registration
+func (s *FileWebhookStore) SaveWebhook(webhook *models.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeJSON(filepath.Join(s.webhooksDir, filepath.Base(webhook.ID)+".json"), webhook)
+}
+
+// DeleteWebhook removes a webhook registration; its deliveries are kept
+func (s *FileWebhookStore) DeleteWebhook(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(filepath.Join(s.webhooksDir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// ListWebhooks returns every registered webhook
+func (s *FileWebhookStore) ListWebhooks() ([]*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var webhooks []*models.Webhook
+	err := readJSONDir(s.webhooksDir, func(data []byte) error {
+		webhook := &models.Webhook{}
+		if err := json.Unmarshal(data, webhook); err != nil {
+			return err
+		}
+		webhooks = append(webhooks, webhook)
+		return nil
+	})
+	return webhooks, err
+}
+
+// SaveDelivery writes a delivery log entry
+func (s *FileWebhookStore) SaveDelivery(delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeJSON(filepath.Join(s.deliveriesDir, filepath.Base(delivery.ID)+".json"), delivery)
+}
+
+// LoadDelivery reads a single delivery log entry
+func (s *FileWebhookStore) LoadDelivery(id string) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.deliveriesDir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{}
+	if err := json.Unmarshal(data, delivery); err != nil {
+		return nil, fmt.Errorf("failed to decode delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns the whole delivery log
+func (s *FileWebhookStore) ListDeliveries() ([]*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []*models.WebhookDelivery
+	err := readJSONDir(s.deliveriesDir, func(data []byte) error {
+		delivery := &models.WebhookDelivery{}
+		if err := json.Unmarshal(data, delivery); err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+		return nil
+	})
+	return deliveries, err
+}
+
+// DeleteDelivery removes a delivery log entry
+func (s *FileWebhookStore) DeleteDelivery(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(filepath.Join(s.deliveriesDir, filepath.Base(id)+".json"))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// SaveCursor records the ID of the last event dispatched to webhooks
+func (s *FileWebhookStore) SaveCursor(eventID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.cursorPath, []byte(strconv.FormatUint(eventID, 10)))
+}
+
+// LoadCursor returns the ID of the last event dispatched to webhooks, or 0 if
+// none has been
+func (s *FileWebhookStore) LoadCursor() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.cursorPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read webhook cursor: %w", err)
+	}
+
+	eventID, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode webhook cursor: %w", err)
+	}
+	return eventID, nil
+}
@@ -0,0 +1,261 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// Granularity selects the period length of a usage breakdown
+type Granularity string
+
+const (
+	Day   Granularity = "day"
+	Month Granularity = "month"
+)
+
+const dayLayout = "2006-01-02"
+
+// Meter records the browser time of ended sessions and keeps running totals
+// per project, region and UTC day. Totals are rebuilt from the durable
+// ledger on startup.
+type Meter struct {
+	store    store.UsageStore
+	mu       sync.RWMutex
+	metered  map[string]bool                 // sessionIDs already in the ledger
+	projects map[string]map[string]*dayUsage // projectID -> day -> usage
+}
+
+// dayUsage is one project's usage over a day (or a longer period when
+// reporting), by region
+type dayUsage struct {
+	runtime  map[string]time.Duration
+	sessions map[string]int // sessions that ended in the period
+}
+
+// NewMeter creates a meter and replays the ledger into its totals
+func NewMeter(usageStore store.UsageStore) (*Meter, error) {
+	m := &Meter{
+		store:    usageStore,
+		metered:  make(map[string]bool),
+		projects: make(map[string]map[string]*dayUsage),
+	}
+
+	records, err := usageStore.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage ledger: %w", err)
+	}
+	for _, record := range records {
+		m.apply(record)
+	}
+
+	return m, nil
+}
+
+// Record meters a session that reached a terminal status. Sessions are
+// metered once, from StartedAt to EndedAt; launches that never produced a
+// browser are not billed.
+func (m *Meter) Record(session *models.Session) error {
+	if session.EndedAt == nil || session.EndReason == models.EndReasonLaunchFailed {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.metered[session.ID] {
+		return nil
+	}
+
+	record := models.UsageRecord{
+		SessionID: session.ID,
+		ProjectID: session.ProjectID,
+		Region:    session.Region,
+		StartedAt: session.StartedAt,
+		EndedAt:   *session.EndedAt,
+	}
+	if err := m.store.Append(record); err != nil {
+		return err
+	}
+
+	m.applyLocked(record)
+	return nil
+}
+
+// apply adds a ledger record to the totals
+func (m *Meter) apply(record models.UsageRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.applyLocked(record)
+}
+
+// applyLocked splits a record's runtime across the UTC days it spans
+func (m *Meter) applyLocked(record models.UsageRecord) {
+	if m.metered[record.SessionID] {
+		return
+	}
+	m.metered[record.SessionID] = true
+
+	days, ok := m.projects[record.ProjectID]
+	if !ok {
+		days = make(map[string]*dayUsage)
+		m.projects[record.ProjectID] = days
+	}
+	usageOn := func(t time.Time) *dayUsage {
+		key := t.UTC().Format(dayLayout)
+		day, ok := days[key]
+		if !ok {
+			day = &dayUsage{runtime: make(map[string]time.Duration), sessions: make(map[string]int)}
+			days[key] = day
+		}
+		return day
+	}
+
+	start, end := record.StartedAt.UTC(), record.EndedAt.UTC()
+	for start.Before(end) {
+		midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+		if midnight.After(end) {
+			midnight = end
+		}
+		usageOn(start).runtime[record.Region] += midnight.Sub(start)
+		start = midnight
+	}
+	usageOn(end).sessions[record.Region]++
+}
+
+// Usage reports a project's metered usage between from and to (inclusive
+// UTC dates; zero values leave the range open), broken down by granularity
+func (m *Meter) Usage(projectID string, granularity Granularity, from, to time.Time) (*models.ProjectUsage, error) {
+	periodLayout := dayLayout
+	switch granularity {
+	case Day:
+	case Month:
+		periodLayout = "2006-01"
+	default:
+		return nil, fmt.Errorf("granularity must be %s or %s", Day, Month)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage := &models.ProjectUsage{
+		ProjectID: projectID,
+		Periods:   []models.UsagePeriod{},
+	}
+
+	periods := make(map[string]*dayUsage)
+	for key, day := range m.projects[projectID] {
+		date, _ := time.Parse(dayLayout, key)
+		if (!from.IsZero() && date.Before(from)) || (!to.IsZero() && date.After(to)) {
+			continue
+		}
+
+		period := date.Format(periodLayout)
+		totals, ok := periods[period]
+		if !ok {
+			totals = &dayUsage{runtime: make(map[string]time.Duration), sessions: make(map[string]int)}
+			periods[period] = totals
+		}
+		for region, runtime := range day.runtime {
+			totals.runtime[region] += runtime
+		}
+		for region, count := range day.sessions {
+			totals.sessions[region] += count
+		}
+	}
+
+	projectRuntime := make(map[string]time.Duration)
+	projectSessions := make(map[string]int)
+	for period, totals := range periods {
+		entry := models.UsagePeriod{
+			Period:  period,
+			Regions: regionUsage(totals.runtime, totals.sessions),
+		}
+		for _, region := range entry.Regions {
+			entry.Sessions += region.Sessions
+		}
+
+		var runtime time.Duration
+		for region, d := range totals.runtime {
+			runtime += d
+			projectRuntime[region] += d
+		}
+		for region, count := range totals.sessions {
+			projectSessions[region] += count
+		}
+		entry.BrowserSeconds = seconds(runtime)
+		entry.BrowserMinutes = Minutes(runtime)
+		usage.Periods = append(usage.Periods, entry)
+	}
+
+	sort.Slice(usage.Periods, func(i, j int) bool {
+		return usage.Periods[i].Period < usage.Periods[j].Period
+	})
+
+	var total time.Duration
+	for _, d := range projectRuntime {
+		total += d
+	}
+	usage.Regions = regionUsage(projectRuntime, projectSessions)
+	for _, region := range usage.Regions {
+		usage.Sessions += region.Sessions
+	}
+	usage.BrowserSeconds = seconds(total)
+	usage.BrowserMinutes = Minutes(total)
+
+	return usage, nil
+}
+
+// MonthToDate returns a project's metered browser time in the UTC calendar
+// month containing now. Sessions still running are not included.
+func (m *Meter) MonthToDate(projectID string, now time.Time) time.Duration {
+	month := now.UTC().Format("2006-01")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var total time.Duration
+	for key, day := range m.projects[projectID] {
+		if !strings.HasPrefix(key, month) {
+			continue
+		}
+		for _, runtime := range day.runtime {
+			total += runtime
+		}
+	}
+	return total
+}
+
+// regionUsage combines per-region runtimes and session counts
+func regionUsage(runtime map[string]time.Duration, sessions map[string]int) map[string]models.RegionUsage {
+	regions := make(map[string]models.RegionUsage)
+	for region, d := range runtime {
+		regions[region] = models.RegionUsage{
+			BrowserMinutes: Minutes(d),
+			BrowserSeconds: seconds(d),
+			Sessions:       sessions[region],
+		}
+	}
+	for region, count := range sessions {
+		if _, ok := regions[region]; !ok {
+			regions[region] = models.RegionUsage{Sessions: count}
+		}
+	}
+	return regions
+}
+
+// seconds rounds a runtime to whole seconds
+func seconds(d time.Duration) int64 {
+	return int64(d.Round(time.Second) / time.Second)
+}
+
+// Minutes rounds a runtime up to whole browser minutes
+func Minutes(d time.Duration) int64 {
+	return int64((d + time.Minute - 1) / time.Minute)
+}
@@ -0,0 +1,512 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/events"
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+const (
+	// maxAttempts bounds automatic retries; failed deliveries can be redelivered by hand
+	maxAttempts = 6
+
+	// baseBackoff doubles after every failed attempt (1s, 2s, 4s, ...)
+	baseBackoff = time.Second
+
+	// maxDeliveries bounds each webhook's log; the oldest finished deliveries
+	// are pruned past it
+	maxDeliveries = 200
+)
+
+// Signature headers sent with every delivery. The signature is the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Manager registers webhooks and delivers bus events to them
+type Manager struct {
+	webhooks   sync.Map // webhookID -> *models.Webhook
+	store      store.WebhookStore
+	bus        *events.Bus
+	client     *http.Client
+	backoff    time.Duration
+	mu         sync.Mutex                           // serializes delivery log updates
+	deliveries map[string][]*models.WebhookDelivery // webhookID -> log snapshots, oldest first
+	cursor     uint64                               // ID of the last event dispatched
+}
+
+// NewManager creates a webhook manager and loads registered webhooks and
+// their delivery logs
+func NewManager(webhookStore store.WebhookStore, bus *events.Bus) (*Manager, error) {
+	m := &Manager{
+		store:      webhookStore,
+		bus:        bus,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		backoff:    baseBackoff,
+		deliveries: make(map[string][]*models.WebhookDelivery),
+	}
+
+	webhooks, err := webhookStore.ListWebhooks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+	for _, webhook := range webhooks {
+		m.webhooks.Store(webhook.ID, webhook)
+	}
+
+	deliveries, err := webhookStore.ListDeliveries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook delivery log: %w", err)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	m.mu.Lock()
+	for _, delivery := range deliveries {
+		m.record(delivery)
+	}
+	m.mu.Unlock()
+
+	cursor, err := webhookStore.LoadCursor()
+	if err != nil {
+		log.Printf("⚠️ Webhooks will not replay missed events: %v", err)
+	}
+	m.cursor = cursor
+
+	return m, nil
+}
+
+// CreateWebhook registers a URL for a project's events
+func (m *Manager) CreateWebhook(projectID string, req models.CreateWebhookRequest) (*models.Webhook, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("projectId is required")
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := &models.Webhook{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		URL:       req.URL,
+		Events:    req.Events,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	if err := m.store.SaveWebhook(webhook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+	m.webhooks.Store(webhook.ID, webhook)
+
+	return webhook, nil
+}
+
+// GetWebhook retrieves a webhook by ID
+func (m *Manager) GetWebhook(id string) (*models.Webhook, error) {
+	value, ok := m.webhooks.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return value.(*models.Webhook), nil
+}
+
+// ListWebhooks returns the webhooks registered for a project. Secrets are
+// masked; they are only returned in full when a webhook is created.
+func (m *Manager) ListWebhooks(projectID string) []*models.Webhook {
+	webhooks := m.projectWebhooks(projectID)
+	for i, webhook := range webhooks {
+		masked := *webhook
+		masked.Secret = maskSecret(webhook.Secret)
+		webhooks[i] = &masked
+	}
+	return webhooks
+}
+
+// projectWebhooks returns the webhooks registered for a project, oldest first
+func (m *Manager) projectWebhooks(projectID string) []*models.Webhook {
+	webhooks := []*models.Webhook{}
+
+	m.webhooks.Range(func(key, value interface{}) bool {
+		webhook := value.(*models.Webhook)
+		if webhook.ProjectID == projectID {
+			webhooks = append(webhooks, webhook)
+		}
+		return true
+	})
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// DeleteWebhook unregisters a webhook; its delivery log is kept
+func (m *Manager) DeleteWebhook(id string) error {
+	if _, err := m.GetWebhook(id); err != nil {
+		return err
+	}
+
+	if err := m.store.DeleteWebhook(id); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	m.webhooks.Delete(id)
+
+	return nil
+}
+
+// ListDeliveries returns a webhook's delivery log, newest first, optionally
+// filtered by status
+func (m *Manager) ListDeliveries(webhookID string, status models.DeliveryStatus) ([]*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := m.deliveries[webhookID]
+	deliveries := []*models.WebhookDelivery{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if status != "" && entries[i].Status != status {
+			continue
+		}
+		deliveries = append(deliveries, entries[i])
+	}
+	return deliveries, nil
+}
+
+// Redeliver schedules a fresh round of attempts for a logged delivery. Only
+// one round runs at a time, so concurrent calls cannot both POST.
+func (m *Manager) Redeliver(webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	webhook, err := m.GetWebhook(webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	var logged *models.WebhookDelivery
+	for _, entry := range m.deliveries[webhookID] {
+		if entry.ID == deliveryID {
+			logged = entry
+			break
+		}
+	}
+	if logged == nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("delivery not found")
+	}
+	if logged.Status == models.DeliveryPending {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("delivery is already in progress")
+	}
+
+	delivery := *logged
+	delivery.Status = models.DeliveryPending
+	delivery.UpdatedAt = time.Now()
+	snapshot := delivery
+	err = m.saveDeliveryLocked(&snapshot)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	go m.deliver(webhook, &delivery)
+	return &snapshot, nil
+}
+
+// Start subscribes to the event bus and resumes deliveries that were still
+// pending when the server last stopped. Events published since the last one
+// dispatched, such as those from restoring and reconciling sessions before
+// Start, are replayed from the bus.
+func (m *Manager) Start(ctx context.Context) {
+	var pending []*models.WebhookDelivery
+	m.mu.Lock()
+	for _, entries := range m.deliveries {
+		for _, entry := range entries {
+			if entry.Status == models.DeliveryPending {
+				delivery := *entry
+				pending = append(pending, &delivery)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, delivery := range pending {
+		if webhook, err := m.GetWebhook(delivery.WebhookID); err == nil {
+			go m.deliver(webhook, delivery)
+		}
+	}
+
+	// Subscribe before returning so no event published after Start is missed
+	sub, missed := m.bus.Subscribe(events.Filter{}, m.cursor)
+	if sub.Truncated {
+		log.Printf("⚠️ Webhook replay after event %d is incomplete; events the bus no longer holds were not delivered", m.cursor)
+	}
+	go m.consume(ctx, sub, missed)
+}
+
+// consume fans bus events out to matching webhooks, starting with the missed
+// events replayed on subscribe. The bus drops slow subscribers, so it
+// resubscribes from the last event it saw.
+func (m *Manager) consume(ctx context.Context, sub *events.Subscription, missed []events.Event) {
+	for _, e := range missed {
+		m.dispatch(e)
+	}
+
+	for {
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				m.bus.Unsubscribe(sub)
+				return
+			case e, ok := <-sub.C:
+				if !ok {
+					break receive
+				}
+				m.dispatch(e)
+			}
+		}
+
+		sub, missed = m.bus.Subscribe(events.Filter{}, m.cursor)
+		if sub.Truncated {
+			log.Printf("⚠️ Webhooks fell behind the event bus; events after %d were not delivered", m.cursor)
+		}
+		for _, e := range missed {
+			m.dispatch(e)
+		}
+	}
+}
+
+// dispatch creates a delivery for every webhook interested in the event
+func (m *Manager) dispatch(e events.Event) {
+	// Advance the cursor only once the deliveries are logged, so a restart
+	// replays an event that was cut off mid-dispatch
+	defer m.advance(e.ID)
+
+	if e.Type == events.SessionCreated {
+		// Only status changes and context saves are delivered
+		return
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode event %d for webhooks: %v", e.ID, err)
+		return
+	}
+
+	for _, webhook := range m.projectWebhooks(e.ProjectID) {
+		if !subscribed(webhook, e.Type) {
+			continue
+		}
+
+		now := time.Now()
+		delivery := &models.WebhookDelivery{
+			ID:        uuid.New().String(),
+			WebhookID: webhook.ID,
+			ProjectID: webhook.ProjectID,
+			EventID:   e.ID,
+			EventType: string(e.Type),
+			Payload:   payload,
+			Status:    models.DeliveryPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if err := m.saveDelivery(delivery); err != nil {
+			log.Printf("⚠️ Failed to log webhook delivery: %v", err)
+			continue
+		}
+
+		go m.deliver(webhook, delivery)
+	}
+}
+
+// advance records eventID as the last event dispatched
+func (m *Manager) advance(eventID uint64) {
+	m.cursor = eventID
+	if err := m.store.SaveCursor(eventID); err != nil {
+		log.Printf("⚠️ Failed to save webhook cursor: %v", err)
+	}
+}
+
+// deliver POSTs the payload until it succeeds or attempts run out
+func (m *Manager) deliver(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(m.backoff << (attempt - 1))
+		}
+
+		statusCode, err := m.post(webhook, delivery)
+
+		delivery.Attempts++
+		delivery.LastStatusCode = statusCode
+		delivery.LastError = ""
+		delivery.UpdatedAt = time.Now()
+		if err != nil {
+			delivery.LastError = err.Error()
+		} else {
+			delivery.Status = models.DeliverySucceeded
+		}
+
+		if err := m.saveDelivery(delivery); err != nil {
+			log.Printf("⚠️ Failed to log webhook delivery %s: %v", delivery.ID[:8], err)
+		}
+		if delivery.Status == models.DeliverySucceeded {
+			return
+		}
+	}
+
+	delivery.Status = models.DeliveryFailed
+	if err := m.saveDelivery(delivery); err != nil {
+		log.Printf("⚠️ Failed to log webhook delivery %s: %v", delivery.ID[:8], err)
+	}
+	log.Printf("❌ Webhook delivery %s to %s failed: %s", delivery.ID[:8], webhook.URL, delivery.LastError)
+}
+
+// post performs a single signed delivery attempt
+func (m *Manager) post(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// saveDelivery persists a delivery snapshot; deliveries are updated from
+// their own goroutines so writes are serialized
+func (m *Manager) saveDelivery(delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := *delivery
+	return m.saveDeliveryLocked(&snapshot)
+}
+
+// saveDeliveryLocked persists a snapshot and puts it in the log. Callers hold
+// mu and must not change the snapshot afterwards.
+func (m *Manager) saveDeliveryLocked(snapshot *models.WebhookDelivery) error {
+	if err := m.store.SaveDelivery(snapshot); err != nil {
+		return err
+	}
+	m.record(snapshot)
+	return nil
+}
+
+// record puts a snapshot in its webhook's log, replacing an earlier one of the
+// same delivery, and prunes the oldest finished deliveries past maxDeliveries.
+// Callers hold mu.
+func (m *Manager) record(snapshot *models.WebhookDelivery) {
+	entries := m.deliveries[snapshot.WebhookID]
+	for i, entry := range entries {
+		if entry.ID == snapshot.ID {
+			entries[i] = snapshot
+			return
+		}
+	}
+	entries = append(entries, snapshot)
+
+	for excess := len(entries) - maxDeliveries; excess > 0; excess-- {
+		i := slices.IndexFunc(entries, func(entry *models.WebhookDelivery) bool {
+			return entry.Status != models.DeliveryPending
+		})
+		if i < 0 {
+			break
+		}
+		if err := m.store.DeleteDelivery(entries[i].ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			log.Printf("⚠️ Failed to prune webhook delivery %s: %v", entries[i].ID[:8], err)
+		}
+		entries = slices.Delete(entries, i, i+1)
+	}
+
+	m.deliveries[snapshot.WebhookID] = entries
+}
+
+// Sign computes the hex HMAC-SHA256 signature receivers should verify
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// subscribed reports whether a webhook wants events of this type
+func subscribed(webhook *models.Webhook, eventType events.Type) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, t := range webhook.Events {
+		if t == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) < 16 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// generateSecret creates a random signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
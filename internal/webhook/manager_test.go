@@ -0,0 +1,312 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/shehryarbajwa/browserbase-mini/internal/events"
+	"github.com/shehryarbajwa/browserbase-mini/internal/store"
+	"github.com/shehryarbajwa/browserbase-mini/pkg/models"
+)
+
+// receiver is a webhook endpoint that fails its first failures requests
+type receiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		if r.failures > 0 {
+			r.failures--
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newTestManager starts a manager over a store in dir with millisecond backoff
+func newTestManager(t *testing.T, dir string, bus *events.Bus) *Manager {
+	t.Helper()
+
+	webhookStore, err := store.NewFileWebhookStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(webhookStore, bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m.Start(ctx)
+	return m
+}
+
+// waitForDelivery polls the log until the webhook's latest delivery finishes
+func waitForDelivery(t *testing.T, m *Manager, webhookID string) *models.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := m.ListDeliveries(webhookID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deliveries) > 0 && deliveries[0].Status != models.DeliveryPending {
+			return deliveries[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("delivery did not finish")
+	return nil
+}
+
+func TestDeliverySignedAndRetried(t *testing.T) {
+	recv := newReceiver(t, 2)
+	bus := events.NewBus(10)
+	dir := t.TempDir()
+	m := newTestManager(t, dir, bus)
+
+	webhook, err := m.CreateWebhook("proj", models.CreateWebhookRequest{URL: recv.server.URL, Events: []string{string(events.SessionCompleted)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unsubscribed event types are not delivered
+	bus.Publish(events.Event{Type: events.SessionRunning, ProjectID: "proj"})
+	bus.Publish(events.Event{Type: events.SessionCompleted, ProjectID: "proj", SessionID: "s1"})
+
+	delivery := waitForDelivery(t, m, webhook.ID)
+	if delivery.Status != models.DeliverySucceeded || delivery.Attempts != 3 || delivery.LastStatusCode != http.StatusOK {
+		t.Fatalf("delivery = %+v, want success on the third attempt", delivery)
+	}
+	if delivery.EventType != string(events.SessionCompleted) {
+		t.Errorf("delivered %s", delivery.EventType)
+	}
+	if n := recv.count(); n != 3 {
+		t.Fatalf("receiver got %d requests, want 3", n)
+	}
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	for i, req := range recv.requests {
+		timestamp := req.Header.Get(HeaderTimestamp)
+		want := "sha256=" + Sign(webhook.Secret, timestamp, recv.bodies[i])
+		if got := req.Header.Get(HeaderSignature); got != want {
+			t.Errorf("attempt %d signature = %q, want %q", i+1, got, want)
+		}
+		if req.Header.Get(HeaderDelivery) != delivery.ID || req.Header.Get(HeaderEvent) != delivery.EventType {
+			t.Errorf("attempt %d headers = %v", i+1, req.Header)
+		}
+	}
+	if Sign("other-secret", recv.requests[0].Header.Get(HeaderTimestamp), recv.bodies[0]) == strings.TrimPrefix(recv.requests[0].Header.Get(HeaderSignature), "sha256=") {
+		t.Error("signature does not depend on the secret")
+	}
+
+	// The log survives a restart
+	restarted := newTestManager(t, dir, events.NewBus(10))
+	deliveries, err := restarted.ListDeliveries(webhook.ID, models.DeliverySucceeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].ID != delivery.ID || deliveries[0].Attempts != 3 {
+		t.Fatalf("persisted log = %+v", deliveries)
+	}
+}
+
+func TestEventsBeforeStartReplayedAfterRestart(t *testing.T) {
+	recv := newReceiver(t, 0)
+	dir := t.TempDir()
+	bus := events.NewBus(10)
+	m := newTestManager(t, dir, bus)
+
+	webhook, err := m.CreateWebhook("proj", models.CreateWebhookRequest{URL: recv.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(events.Event{Type: events.SessionCompleted, ProjectID: "proj", SessionID: "s1"})
+	waitForDelivery(t, m, webhook.ID)
+
+	// Sessions restored and reconciled on restart publish before webhooks start
+	restartedBus := events.NewBus(10)
+	time.Sleep(time.Millisecond)
+	missed := restartedBus.Publish(events.Event{Type: events.SessionError, ProjectID: "proj", SessionID: "s2"})
+	restarted := newTestManager(t, dir, restartedBus)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for recv.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	delivery := waitForDelivery(t, restarted, webhook.ID)
+	if delivery.EventID != missed.ID || delivery.Status != models.DeliverySucceeded {
+		t.Fatalf("latest delivery = %+v, want event %d delivered", delivery, missed.ID)
+	}
+}
+
+func TestDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	recv := newReceiver(t, maxAttempts+1)
+	bus := events.NewBus(10)
+	m := newTestManager(t, t.TempDir(), bus)
+
+	webhook, err := m.CreateWebhook("proj", models.CreateWebhookRequest{URL: recv.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(events.Event{Type: events.SessionError, ProjectID: "proj"})
+
+	delivery := waitForDelivery(t, m, webhook.ID)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != maxAttempts || delivery.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("delivery = %+v, want failure after %d attempts", delivery, maxAttempts)
+	}
+	if !strings.Contains(delivery.LastError, "503") {
+		t.Errorf("lastError = %q", delivery.LastError)
+	}
+
+	// Redelivery starts a fresh round; the receiver has one failure left
+	redelivered, err := m.Redeliver(webhook.ID, delivery.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivered.Status != models.DeliveryPending {
+		t.Errorf("redelivery status = %s", redelivered.Status)
+	}
+	delivery = waitForDelivery(t, m, webhook.ID)
+	if delivery.Status != models.DeliverySucceeded || delivery.Attempts != maxAttempts+2 {
+		t.Fatalf("redelivered = %+v", delivery)
+	}
+	if n := recv.count(); n != maxAttempts+2 {
+		t.Errorf("receiver got %d requests, want %d", n, maxAttempts+2)
+	}
+
+	if _, err := m.Redeliver(webhook.ID, "missing"); err == nil {
+		t.Error("redelivered an unknown delivery")
+	}
+}
+
+func TestConcurrentRedeliverPostsOnce(t *testing.T) {
+	recv := newReceiver(t, maxAttempts)
+	bus := events.NewBus(10)
+	m := newTestManager(t, t.TempDir(), bus)
+
+	webhook, err := m.CreateWebhook("proj", models.CreateWebhookRequest{URL: recv.server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(events.Event{Type: events.SessionCompleted, ProjectID: "proj"})
+	delivery := waitForDelivery(t, m, webhook.ID)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Redeliver(webhook.ID, delivery.ID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	started := 0
+	for err := range errs {
+		if err == nil {
+			started++
+		}
+	}
+	if started != 1 {
+		t.Fatalf("%d redeliveries started, want 1", started)
+	}
+
+	waitForDelivery(t, m, webhook.ID)
+	if n := recv.count(); n != maxAttempts+1 {
+		t.Errorf("receiver got %d requests, want %d", n, maxAttempts+1)
+	}
+}
+
+func TestListWebhooksMasksSecret(t *testing.T) {
+	m := newTestManager(t, t.TempDir(), events.NewBus(10))
+
+	created, err := m.CreateWebhook("proj", models.CreateWebhookRequest{URL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Secret, "whsec_") {
+		t.Fatalf("created secret = %q", created.Secret)
+	}
+
+	listed := m.ListWebhooks("proj")
+	if len(listed) != 1 {
+		t.Fatalf("listed %d webhooks", len(listed))
+	}
+	if listed[0].Secret == created.Secret || !strings.HasSuffix(created.Secret, strings.TrimPrefix(listed[0].Secret, "****")) {
+		t.Errorf("listed secret = %q", listed[0].Secret)
+	}
+
+	// Deliveries still sign with the real secret
+	if webhook, _ := m.GetWebhook(created.ID); webhook.Secret != created.Secret {
+		t.Error("masking changed the stored secret")
+	}
+}
+
+func TestDeliveryLogPruned(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(t, dir, events.NewBus(10))
+
+	pending := &models.WebhookDelivery{ID: uuid.New().String(), WebhookID: "hook", Status: models.DeliveryPending, CreatedAt: time.Now()}
+	if err := m.saveDelivery(pending); err != nil {
+		t.Fatal(err)
+	}
+	var first *models.WebhookDelivery
+	for i := 0; i < maxDeliveries+5; i++ {
+		delivery := &models.WebhookDelivery{ID: uuid.New().String(), WebhookID: "hook", Status: models.DeliverySucceeded, CreatedAt: time.Now()}
+		if first == nil {
+			first = delivery
+		}
+		if err := m.saveDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliveries, err := m.ListDeliveries("hook", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != maxDeliveries {
+		t.Fatalf("log holds %d deliveries, want %d", len(deliveries), maxDeliveries)
+	}
+	if pendingLeft, _ := m.ListDeliveries("hook", models.DeliveryPending); len(pendingLeft) != 1 {
+		t.Error("a pending delivery was pruned")
+	}
+	if _, err := m.store.LoadDelivery(first.ID); err != store.ErrNotFound {
+		t.Errorf("oldest delivery still on disk: %v", err)
+	}
+}
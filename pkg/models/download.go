@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Download is a file the browser saved during a session
+type Download struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// Upload is a file made available to a session's browser for file inputs
+type Upload struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // Absolute path inside the browser container
+	Size int64  `json:"size"`
+}
+
+// SetInputFilesRequest is the payload for attaching uploads to a file input
+type SetInputFilesRequest struct {
+	Selector string   `json:"selector"`
+	Files    []string `json:"files"` // In-container paths returned by the upload endpoint
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LogEntry is a console message, uncaught exception or browser log entry
+// captured from a session's pages
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`  // debug, info, warning or error
+	Source    string    `json:"source"` // console, exception, or the Log domain source (network, security, ...)
+	Text      string    `json:"text"`
+	URL       string    `json:"url,omitempty"`
+	Line      int       `json:"line,omitempty"`
+}
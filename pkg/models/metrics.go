@@ -0,0 +1,47 @@
+package models
+
+// Metrics is a point-in-time snapshot of server load
+type Metrics struct {
+	Admission AdmissionMetrics  `json:"admission"`
+	WarmPools []WarmPoolMetrics `json:"warmPools"`
+	Projects  []ProjectMetrics  `json:"projects"`
+}
+
+// WarmPoolMetrics reports a region's pool of pre-started containers
+type WarmPoolMetrics struct {
+	Region   string `json:"region"`
+	Size     int    `json:"size"`
+	Idle     int    `json:"idle"`
+	Starting int    `json:"starting"`
+	Hits     int64  `json:"hits"`   // launches that took a warm container
+	Misses   int64  `json:"misses"` // launches that found the pool empty
+}
+
+// AdmissionMetrics reports host-wide session capacity
+type AdmissionMetrics struct {
+	Capacity          int   `json:"capacity"`
+	InUse             int   `json:"inUse"`
+	Reserved          int   `json:"reserved"` // held back for interactive sessions
+	QueuedInteractive int   `json:"queuedInteractive"`
+	QueuedBatch       int   `json:"queuedBatch"`
+	Rejected          int64 `json:"rejected"` // since the server started
+}
+
+// ProjectMetrics reports a project's concurrency slots and slot queue
+type ProjectMetrics struct {
+	ProjectID     string          `json:"projectId"`
+	Concurrency   int             `json:"concurrency"`
+	Weight        int             `json:"weight"`
+	ActiveSlots   int             `json:"activeSlots"`
+	QueueDepth    int             `json:"queueDepth"`
+	OldestWaitMs  int64           `json:"oldestWaitMs"`
+	QueueTimeouts int64           `json:"queueTimeouts"` // requests that gave up waiting since the server started
+	Queue         []QueuedRequest `json:"queue"`
+}
+
+// QueuedRequest is one create request waiting for a concurrency slot
+type QueuedRequest struct {
+	Position  int    `json:"position"`
+	SessionID string `json:"sessionId,omitempty"` // async requests only
+	WaitingMs int64  `json:"waitingMs"`
+}
@@ -4,17 +4,102 @@ import "time"
 
 // Project represents a customer project with resource limits
 type Project struct {
-	ID             string    `json:"id"`
-	Name           string    `json:"name"`
-	Concurrency    int       `json:"concurrency"`
-	DefaultTimeout int       `json:"defaultTimeout"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Concurrency    int            `json:"concurrency"`
+	DefaultTimeout int            `json:"defaultTimeout"`
+	Weight         int            `json:"weight"`    // share of host capacity relative to other projects
+	Resources      ResourceLimits `json:"resources"` // default limits for the project's browsers
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
 }
 
-// ProjectUsage tracks resource consumption for a project
+// ResourceLimits caps the host resources a session's browser may use. Zero
+// fields are unconstrained; in a session request they inherit the project's
+// default.
+type ResourceLimits struct {
+	CPUShares int64   `json:"cpuShares,omitempty"` // relative CPU weight; Docker's default is 1024
+	CPUs      float64 `json:"cpus,omitempty"`      // hard CPU quota in cores, e.g. 1.5
+	MemoryMB  int64   `json:"memoryMb,omitempty"`  // the browser is OOM-killed above this
+	ShmSizeMB int64   `json:"shmSizeMb,omitempty"` // size of /dev/shm; Docker's default is 64
+	PidsLimit int64   `json:"pidsLimit,omitempty"` // processes and threads
+}
+
+// CreateProjectRequest registers a project. ID is generated when empty;
+// zero limits and omitted resources take the server defaults.
+type CreateProjectRequest struct {
+	ID             string          `json:"id,omitempty"`
+	Name           string          `json:"name"`
+	Concurrency    int             `json:"concurrency,omitempty"`
+	DefaultTimeout int             `json:"defaultTimeout,omitempty"`
+	Weight         int             `json:"weight,omitempty"`
+	Resources      *ResourceLimits `json:"resources,omitempty"`
+}
+
+// UpdateProjectRequest changes a project; omitted fields are left as they are
+type UpdateProjectRequest struct {
+	Name           *string         `json:"name,omitempty"`
+	Concurrency    *int            `json:"concurrency,omitempty"`
+	DefaultTimeout *int            `json:"defaultTimeout,omitempty"`
+	Weight         *int            `json:"weight,omitempty"`
+	Resources      *ResourceLimits `json:"resources,omitempty"` // replaces every default limit
+}
+
+// ProjectUsage tracks resource consumption for a project. Totals cover
+// sessions that have ended; ActiveSessions counts those still running.
 type ProjectUsage struct {
-	ProjectID      string `json:"projectId"`
-	BrowserMinutes int64  `json:"browserMinutes"`
-	ActiveSessions int    `json:"activeSessions"`
+	ProjectID      string                 `json:"projectId"`
+	BrowserMinutes int64                  `json:"browserMinutes"` // rounded up
+	BrowserSeconds int64                  `json:"browserSeconds"`
+	Sessions       int                    `json:"sessions"`
+	ActiveSessions int                    `json:"activeSessions"`
+	Regions        map[string]RegionUsage `json:"regions"`
+	Periods        []UsagePeriod          `json:"periods"`
+}
+
+// UsagePeriod is the usage of one day ("2006-01-02") or month ("2006-01") in UTC
+type UsagePeriod struct {
+	Period         string                 `json:"period"`
+	BrowserMinutes int64                  `json:"browserMinutes"`
+	BrowserSeconds int64                  `json:"browserSeconds"`
+	Sessions       int                    `json:"sessions"` // sessions that ended in the period
+	Regions        map[string]RegionUsage `json:"regions"`
+}
+
+// RegionUsage is the share of a usage total spent in one region
+type RegionUsage struct {
+	BrowserMinutes int64 `json:"browserMinutes"`
+	BrowserSeconds int64 `json:"browserSeconds"`
+	Sessions       int   `json:"sessions"`
+}
+
+// UsageRecord is the metered runtime of one session, written once it ends
+type UsageRecord struct {
+	SessionID string    `json:"sessionId"`
+	ProjectID string    `json:"projectId"`
+	Region    string    `json:"region"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+}
+
+// ProjectQuota caps a project's consumption. Zero leaves a limit unset.
+type ProjectQuota struct {
+	ProjectID             string    `json:"projectId"`
+	MonthlyBrowserMinutes int64     `json:"monthlyBrowserMinutes"` // per UTC calendar month
+	ContextStorageBytes   int64     `json:"contextStorageBytes"`   // total size of saved context archives
+	UpdatedAt             time.Time `json:"updatedAt"`
+}
+
+// SetQuotaRequest replaces a project's quota
+type SetQuotaRequest struct {
+	MonthlyBrowserMinutes int64 `json:"monthlyBrowserMinutes"`
+	ContextStorageBytes   int64 `json:"contextStorageBytes"`
+}
+
+// QuotaStatus reports a project's quota alongside what it has used. Browser
+// minutes cover the current UTC month, including sessions still running.
+type QuotaStatus struct {
+	ProjectQuota
+	BrowserMinutesUsed int64 `json:"browserMinutesUsed"`
+	ContextStorageUsed int64 `json:"contextStorageUsed"`
 }
@@ -6,31 +6,134 @@ import "time"
 type SessionStatus string
 
 const (
+	StatusPending   SessionStatus = "PENDING"
+	StatusStarting  SessionStatus = "STARTING"
 	StatusRunning   SessionStatus = "RUNNING"
 	StatusCompleted SessionStatus = "COMPLETED"
 	StatusError     SessionStatus = "ERROR"
 	StatusTimedOut  SessionStatus = "TIMED_OUT"
+
+	// StatusRequestRelease is only valid in an update request and ends the session
+	StatusRequestRelease SessionStatus = "REQUEST_RELEASE"
+)
+
+// EndReason explains why a session reached a terminal status
+type EndReason string
+
+const (
+	EndReasonRequested          EndReason = "REQUESTED"
+	EndReasonTimeout            EndReason = "TIMEOUT"
+	EndReasonIdleTimeout        EndReason = "IDLE_TIMEOUT"
+	EndReasonBrowserExit        EndReason = "BROWSER_EXITED"
+	EndReasonLaunchFailed       EndReason = "LAUNCH_FAILED"
+	EndReasonClientDisconnected EndReason = "CLIENT_DISCONNECTED"
+	EndReasonQuotaExhausted     EndReason = "QUOTA_EXHAUSTED"
+	EndReasonOOMKilled          EndReason = "OOM_KILLED"
+)
+
+// Priority is the admission class of a session when host capacity is short
+type Priority string
+
+const (
+	// PriorityInteractive sessions are admitted ahead of batch work and may
+	// use the capacity held back from batch
+	PriorityInteractive Priority = "interactive"
+	PriorityBatch       Priority = "batch"
 )
 
 // Session represents an active browser instance
 type Session struct {
-	ID          string        `json:"id"`
-	ProjectID   string        `json:"projectId"`
-	Status      SessionStatus `json:"status"`
-	Region      string        `json:"region"`
-	StartedAt   time.Time     `json:"startedAt"`
-	ExpiresAt   time.Time     `json:"expiresAt"`
-	Timeout     int           `json:"timeout"`
-	ConnectURL  string        `json:"connectUrl"`
-	ContainerID string        `json:"-"`
-	ContextID   string        `json:"contextId,omitempty"`
-	UserDataDir string        `json:"-"` // NEW: Track user data directory
+	ID                   string            `json:"id"`
+	ProjectID            string            `json:"projectId"`
+	Status               SessionStatus     `json:"status"`
+	Region               string            `json:"region"`
+	StartedAt            time.Time         `json:"startedAt"`
+	ExpiresAt            time.Time         `json:"expiresAt"`
+	Timeout              int               `json:"timeout"`
+	ConnectURL           string            `json:"connectUrl"` // Proxy endpoint clients connect and reconnect through
+	BrowserURL           string            `json:"-"`          // The container's own CDP endpoint
+	ContainerID          string            `json:"-"`
+	ContextID            string            `json:"contextId,omitempty"`
+	UserDataDir          string            `json:"-"` // NEW: Track user data directory
+	DownloadPath         string            `json:"-"` // Where the browser sees the download directory
+	UploadPath           string            `json:"-"` // Where the browser sees uploaded files
+	IdleTimeout          int               `json:"idleTimeout,omitempty"`
+	RecordResponseBodies bool              `json:"recordResponseBodies,omitempty"`
+	EndReason            EndReason         `json:"endReason,omitempty"`
+	ErrorReason          string            `json:"errorReason,omitempty"`
+	ExitCode             *int              `json:"exitCode,omitempty"`
+	EndedAt              *time.Time        `json:"endedAt,omitempty"`
+	UserMetadata         map[string]string `json:"userMetadata,omitempty"`
+	KeepAlive            bool              `json:"keepAlive,omitempty"`
+	Priority             Priority          `json:"priority,omitempty"`
+	Queue                *QueueInfo        `json:"queue,omitempty"`     // Set when creation waited for a concurrency slot
+	Resources            *ResourceLimits   `json:"resources,omitempty"` // Limits the browser runs under
+	Stats                *SessionStats     `json:"stats,omitempty"`     // Resource usage, rolled up when the session ends
+}
+
+// ResourceStats is one reading of a session browser's resource usage.
+// Network and block IO are totals since the browser started.
+type ResourceStats struct {
+	Timestamp        time.Time `json:"timestamp"`
+	CPUPercent       float64   `json:"cpuPercent"` // 100 is one core fully busy
+	MemoryBytes      int64     `json:"memoryBytes"`
+	MemoryLimitBytes int64     `json:"memoryLimitBytes"`
+	NetworkRxBytes   int64     `json:"networkRxBytes"`
+	NetworkTxBytes   int64     `json:"networkTxBytes"`
+	BlockReadBytes   int64     `json:"blockReadBytes"`
+	BlockWriteBytes  int64     `json:"blockWriteBytes"`
+}
+
+// SessionStats summarises the readings taken over a session's life
+type SessionStats struct {
+	PeakMemoryBytes int64     `json:"peakMemoryBytes"`
+	PeakCPUPercent  float64   `json:"peakCpuPercent"`
+	AvgCPUPercent   float64   `json:"avgCpuPercent"`
+	NetworkRxBytes  int64     `json:"networkRxBytes"`
+	NetworkTxBytes  int64     `json:"networkTxBytes"`
+	BlockReadBytes  int64     `json:"blockReadBytes"`
+	BlockWriteBytes int64     `json:"blockWriteBytes"`
+	Samples         int       `json:"samples"`
+	LastSampleAt    time.Time `json:"lastSampleAt"`
+}
+
+// QueueInfo describes a create request's place in its project's FIFO queue
+// for concurrency slots. While an async session is queued Position is live;
+// afterwards it records where the request joined the queue.
+type QueueInfo struct {
+	Position int   `json:"position"` // 1 is next in line
+	Depth    int   `json:"depth"`    // requests waiting in the project's queue
+	WaitedMs int64 `json:"waitedMs"`
 }
 
 // CreateSessionRequest is the payload for creating a new session
 type CreateSessionRequest struct {
-	ProjectID string `json:"projectId"`
-	Region    string `json:"region,omitempty"`
-	Timeout   int    `json:"timeout,omitempty"`
-	ContextID string `json:"contextId,omitempty"`
+	ProjectID            string            `json:"projectId"`
+	Region               string            `json:"region,omitempty"`
+	Timeout              int               `json:"timeout,omitempty"`
+	ContextID            string            `json:"contextId,omitempty"`
+	IdleTimeout          int               `json:"idleTimeout,omitempty"`          // End the session after this many seconds without clients or commands
+	RecordResponseBodies bool              `json:"recordResponseBodies,omitempty"` // Include response bodies up to 1MB in the HAR
+	Async                bool              `json:"async,omitempty"`                // Return PENDING immediately and launch in the background
+	UserMetadata         map[string]string `json:"userMetadata,omitempty"`         // Caller-defined labels, filterable in list calls
+	KeepAlive            bool              `json:"keepAlive,omitempty"`            // Keep running when the last CDP client disconnects
+	WaitTimeout          int               `json:"waitTimeout,omitempty"`          // Seconds to queue for a concurrency slot instead of failing at once
+	Priority             Priority          `json:"priority,omitempty"`             // interactive (default) or batch
+	Resources            *ResourceLimits   `json:"resources,omitempty"`            // Overrides the project's default limits field by field
+}
+
+// UpdateSessionRequest is the payload for changing a live session. Timeout is
+// the new total lifetime measured from startedAt; ExtendBy adds seconds to the
+// current expiry. Both are capped at 21600 seconds of total lifetime.
+type UpdateSessionRequest struct {
+	Timeout     *int          `json:"timeout,omitempty"`
+	ExtendBy    int           `json:"extendBy,omitempty"`
+	IdleTimeout *int          `json:"idleTimeout,omitempty"` // 0 disables the idle timeout
+	Status      SessionStatus `json:"status,omitempty"`
+}
+
+// SessionPage is one page of a paginated session list
+type SessionPage struct {
+	Data       []*Session `json:"data"`
+	NextCursor string     `json:"nextCursor,omitempty"` // Empty on the last page
 }
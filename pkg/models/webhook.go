@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is a project-registered URL that receives event notifications
+type Webhook struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"projectId"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"` // Empty means all events
+	Secret    string    `json:"secret"`           // HMAC-SHA256 signing key; masked in lists
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateWebhookRequest is the payload for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"` // Generated when omitted
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryFailed    DeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery records the attempts to POST one event to one webhook
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookId"`
+	ProjectID      string          `json:"projectId"`
+	EventID        uint64          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
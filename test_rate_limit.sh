@@ -1,5 +1,10 @@
 #!/bin/bash
 
+# Sessions can only be created in registered projects
+curl -s -o /dev/null -X POST http://localhost:8080/v1/projects \
+  -H "Content-Type: application/json" \
+  -d '{"id":"proj-rate-test","concurrency":10}'
+
 echo "Testing rate limit..."
 echo ""
 